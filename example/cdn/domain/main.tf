@@ -252,6 +252,13 @@ resource "wangsu_cdn_domain" "domain20240712001" {
     enable_http2            = "true"
     back_to_origin_protocol = "http2.0"
   }
+  compression_settings {
+    compression_types = ["brotli", "gzip"]
+    min_file_size     = "1024"
+    max_file_size     = "10485760"
+    content_types     = "text/html;text/css;application/javascript;application/json"
+    path_pattern      = ".*"
+  }
   header_modify_rules {
     path_pattern          = "*.jpg"
     except_path_pattern   = "abc.jpg"
@@ -397,6 +404,25 @@ resource "wangsu_cdn_domain" "domain20240712001" {
     exception_request_header = "exception_request_header"
     priority                 = "5"
   }
+  visit_control_rules {
+    path_pattern       = "/video/.*"
+    allow_null_referer = "false"
+    valid_referer      = "www.example.com;.*\\.example\\.com"
+    invalid_ips        = "1.1.1.1;2.2.2.0/24"
+    control_action     = "403"
+    priority           = "10"
+  }
+  visit_control_rules {
+    file_type           = "mp4;flv"
+    invalid_user_agents = ".*curl.*;.*wget.*"
+  }
+  url_auth_rules {
+    auth_type   = "typeA"
+    primary_key = "primarykey123"
+    backup_key  = "backupkey456"
+    ttl         = "1800"
+    directory   = "/video/"
+  }
   back_to_origin_rewrite_rule {
     protocol = "https"
     port     = "8443"
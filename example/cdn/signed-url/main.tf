@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    wangsu = {
+      source = "registry.terraform.io/wangsu-api/wangsu"
+    }
+  }
+}
+
+provider "wangsu" {
+  secret_id  = "my-secret-id"
+  secret_key = "my-secret-key"
+}
+
+variable "url_auth_key" {
+  type      = string
+  sensitive = true
+}
+
+data "wangsu_cdn_signed_url" "test-video" {
+  url       = "http://20240712001.conftest.com/video/test.mp4"
+  auth_type = "typeA"
+  key       = var.url_auth_key
+  timestamp = 1735689600
+}
+
+output "show-signed-url" {
+  value = data.wangsu_cdn_signed_url.test-video.signed_url
+}
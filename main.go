@@ -17,12 +17,16 @@ import (
 // Before executing this command, please set debuggable to false
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs generate -provider-name wangsu
 
+// version is set at build time, e.g. by goreleaser with -X main.version=1.2.0.
+var version = "dev"
+
 func main() {
 	var debugMode bool
 
 	flag.BoolVar(&debugMode, "debuggable", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
+	wangsu.ProviderVersion = version
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: wangsu.Provider,
 		ProviderAddr: "registry.terraform.io/wangsu-api/wangsu",
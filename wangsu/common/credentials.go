@@ -0,0 +1,101 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
+	"github.com/wangsu-api/wangsu-sdk-go/wangsu/usermanage"
+)
+
+// credentialErrorCodes are the error codes the API gateway returns when it
+// rejects the credentials of a request, mapped to the problem they report. The
+// gateway checks them before routing the request to a service.
+var credentialErrorCodes = map[string]string{
+	"RequestTimeTooSkewed": "the request time was rejected, check that the local clock is in sync",
+	"InvalidTimestamp":     "the request time was rejected, check that the local clock is in sync",
+	"RequestExpired":       "the request time was rejected, check that the local clock is in sync",
+
+	"SignatureDoesNotMatch": "the request signature does not match, check secret_key",
+	"InvalidSignature":      "the request signature does not match, check secret_key",
+	"IncompleteSignature":   "the request signature does not match, check secret_key",
+
+	"InvalidAccessKeyId": "the access key is unknown or disabled, check secret_id",
+	"AccessKeyNotFound":  "the access key is unknown or disabled, check secret_id",
+	"AccessKeyDisabled":  "the access key is unknown or disabled, check secret_id",
+
+	"AuthFailure":          "authentication failed, check secret_id and secret_key",
+	"AuthenticationFailed": "authentication failed, check secret_id and secret_key",
+	"Unauthorized":         "authentication failed, check secret_id and secret_key",
+}
+
+// permissionDeniedCodes are the error codes of a request whose credentials were
+// accepted but whose account may not call the API, e.g. a sub-account without
+// the permission.
+var permissionDeniedCodes = map[string]bool{
+	"AccessDenied":          true,
+	"Forbidden":             true,
+	"NoPermission":          true,
+	"UnauthorizedOperation": true,
+}
+
+// ValidateCredentials sends one lightweight authenticated request, listing the
+// first sub-account, and reports why it was rejected. Every account has the user
+// management API, and the gateway authenticates a request before it checks its
+// permissions, so a sub-account that may not list users still proves that the
+// credentials are valid: the denial is only reported as a warning. Any other
+// error, e.g. a validation error, is not about the credentials and is ignored.
+func ValidateCredentials(ctx context.Context, conn *connectivity.WangSuClient) diag.Diagnostics {
+	client, err := conn.UseUserManageClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	pageSize, pageIndex := 1, 1
+	err = conn.Call(ctx, connectivity.EndpointIam, "ListUsers", func() (string, error) {
+		requestId, _, err := client.ListUsers(&usermanage.GetSubAccountListRequest{
+			PageSize:  &pageSize,
+			PageIndex: &pageIndex,
+		})
+		return requestId, NewAPIError(err, requestId)
+	})
+	return credentialDiagnostics(err, conn.APIDomain(connectivity.EndpointIam))
+}
+
+// credentialDiagnostics classifies the error of the validation request sent to
+// apiDomain by its code, or by its HTTP status when it carries no code.
+func credentialDiagnostics(err error, apiDomain string) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return diag.Errorf("failed to validate credentials: API domain %s is unreachable: %s", apiDomain, err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		log.Printf("[DEBUG] credentials accepted, the validation request failed for another reason: %s", err)
+		return nil
+	}
+	if problem, ok := credentialErrorCodes[apiErr.Code]; ok {
+		return diag.Errorf("failed to validate credentials: %s: %s", problem, apiErr)
+	}
+	if permissionDeniedCodes[apiErr.Code] || (apiErr.Code == "" && apiErr.HttpStatus == http.StatusForbidden) {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "The credentials could not be fully validated",
+			Detail:   fmt.Sprintf("The credentials were accepted, but the account may not list its sub-accounts, the request used to validate them: %s. Resources the account has no permission for will fail when they are applied.", apiErr),
+		}}
+	}
+	if apiErr.Code == "" && apiErr.HttpStatus == http.StatusUnauthorized {
+		return diag.Errorf("failed to validate credentials: authentication failed, check secret_id and secret_key: %s", apiErr)
+	}
+	log.Printf("[DEBUG] credentials accepted, the validation request failed for another reason: %s", apiErr)
+	return nil
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestCredentialDiagnostics(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		severity diag.Severity
+		want     bool
+	}{
+		{"accepted", nil, 0, false},
+		{"signature", &APIError{Code: "SignatureDoesNotMatch", HttpStatus: 401}, diag.Error, true},
+		{"clock skew", errors.New(`{"code":"RequestTimeTooSkewed","message":"x-cnc-date is too far from the server time"}`), diag.Error, true},
+		{"unknown access key", &APIError{Code: "InvalidAccessKeyId", HttpStatus: 401}, diag.Error, true},
+		{"bare 401", &APIError{HttpStatus: 401, RequestId: "r-1"}, diag.Error, true},
+		{"permission denied", &APIError{Code: "AccessDenied", HttpStatus: 403}, diag.Warning, true},
+		{"bare 403", &APIError{HttpStatus: 403, RequestId: "r-1"}, diag.Warning, true},
+		{"unreachable", &url.Error{Op: "Post", URL: "https://open.chinanetcenter.com", Err: errors.New("connection refused")}, diag.Error, true},
+		{"message mentions a signature", &APIError{Code: "InvalidParameter", Message: "signature of the certificate is invalid", HttpStatus: 400}, 0, false},
+		{"service error", &APIError{Code: "ServiceNotOpened", HttpStatus: 400}, 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			diags := credentialDiagnostics(NewAPIError(c.err, ""), "open.chinanetcenter.com")
+			if !c.want {
+				if len(diags) != 0 {
+					t.Errorf("diagnostics = %v, want none", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Severity != c.severity {
+				t.Errorf("diagnostics = %v, want one of severity %v", diags, c.severity)
+			}
+		})
+	}
+}
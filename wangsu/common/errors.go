@@ -0,0 +1,207 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// APIError is an error returned by the Wangsu OpenAPI, broken down into the parts
+// users and support need.
+type APIError struct {
+	Code       string
+	Message    string
+	RequestId  string
+	HttpStatus int
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if e.Code != "" {
+		message = fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	}
+	if e.RequestId != "" {
+		message = fmt.Sprintf("%s (request id: %s)", message, e.RequestId)
+	}
+	return message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status of the failed response, 0 when it is not
+// known. connectivity.WangSuClient.Call retries a call by it.
+func (e *APIError) StatusCode() int {
+	return e.HttpStatus
+}
+
+var (
+	errorCodePattern    = regexp.MustCompile(`(?i)"?\b(?:error_?)?code"?\s*[:=]\s*"?([A-Za-z][\w.-]*)`)
+	errorMessagePattern = regexp.MustCompile(`(?i)"?\b(?:error_?)?(?:message|msg)"?\s*[:=]\s*"?([^"}\n]+?)\s*(?:,\s*[\w-]+"?\s*[:=]|["}\n]|$)`)
+	requestIdPattern    = regexp.MustCompile(`(?i)"?\b(?:x-cnc-)?request[-_ ]?id"?\s*[:=]\s*"?([\w-]+)`)
+	httpStatusPattern   = regexp.MustCompile(`(?i)\bstatus(?:[-_ ]?code)?"?\s*[:=]?\s*(\d{3})\b`)
+	parameterPattern    = regexp.MustCompile("[\\[\"'`]([A-Za-z][A-Za-z0-9]*(?:\\.[A-Za-z][A-Za-z0-9]*)*)[\\]\"'`]")
+	validationPattern   = regexp.MustCompile(`(?i)invalid|missing|param|validat|illegal|required`)
+)
+
+// NewAPIError parses an SDK error. requestId is the id returned alongside the
+// error, when the SDK call returns one; otherwise it is looked up in the error.
+// Errors that are already an *APIError, nil, and errors that carry neither an
+// error code nor a request id, such as network errors, are returned unchanged.
+func NewAPIError(err error, requestId string) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.RequestId == "" {
+			apiErr.RequestId = requestId
+		}
+		return err
+	}
+
+	apiErr = &APIError{RequestId: requestId, Err: err}
+	text := err.Error()
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		apiErr.parseJSON([]byte(text[start : end+1]))
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = firstSubmatch(errorCodePattern, text)
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(firstSubmatch(errorMessagePattern, text))
+	}
+	if apiErr.RequestId == "" {
+		apiErr.RequestId = firstSubmatch(requestIdPattern, text)
+	}
+	var statusErr interface{ StatusCode() int }
+	if apiErr.HttpStatus == 0 && errors.As(err, &statusErr) {
+		apiErr.HttpStatus = statusErr.StatusCode()
+	}
+	if apiErr.HttpStatus == 0 {
+		apiErr.HttpStatus, _ = strconv.Atoi(firstSubmatch(httpStatusPattern, text))
+	}
+	if apiErr.Code == "" && apiErr.RequestId == "" {
+		return err
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = text
+	}
+	return apiErr
+}
+
+func (e *APIError) parseJSON(body []byte) {
+	var payload map[string]interface{}
+	if json.Unmarshal(body, &payload) != nil {
+		return
+	}
+	if nested, ok := payload["error"].(map[string]interface{}); ok {
+		for key, value := range nested {
+			payload[key] = value
+		}
+	}
+	e.Code = stringField(payload, "code", "Code", "errorCode", "error_code")
+	e.Message = stringField(payload, "message", "Message", "msg", "errorMessage", "error_message")
+	if e.RequestId == "" {
+		e.RequestId = stringField(payload, "requestId", "RequestId", "request_id", "x-cnc-request-id")
+	}
+	if status, err := strconv.Atoi(stringField(payload, "httpStatus", "status", "statusCode")); err == nil {
+		e.HttpStatus = status
+	}
+}
+
+// DiagnosticsFromError turns an error into diagnostics. API errors carry their
+// request id in the detail, and validation errors that name a request parameter
+// point at the matching attribute of the resource when there is one.
+func DiagnosticsFromError(data *schema.ResourceData, err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return diag.FromErr(err)
+	}
+
+	summary := apiErr.Message
+	if apiErr.Code != "" {
+		summary = fmt.Sprintf("%s: %s", apiErr.Code, apiErr.Message)
+	}
+	diagnostic := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  summary,
+	}
+	if apiErr.RequestId != "" {
+		diagnostic.Detail = fmt.Sprintf("Request ID: %s. Please include it when contacting Wangsu support.", apiErr.RequestId)
+	}
+	if apiErr.isValidation() {
+		diagnostic.AttributePath = attributePath(data, apiErr.Message)
+	}
+	return diag.Diagnostics{diagnostic}
+}
+
+func (e *APIError) isValidation() bool {
+	return e.HttpStatus == 400 || validationPattern.MatchString(e.Code)
+}
+
+// attributePath returns the path of the first top-level attribute of the resource
+// whose API parameter name, e.g. originConfig.originIps, is quoted in message.
+func attributePath(data *schema.ResourceData, message string) cty.Path {
+	if data == nil {
+		return nil
+	}
+	config := data.GetRawConfig()
+	if config.IsNull() || !config.Type().IsObjectType() {
+		return nil
+	}
+	for _, match := range parameterPattern.FindAllStringSubmatch(message, -1) {
+		name := camelToSnake(strings.SplitN(match[1], ".", 2)[0])
+		if config.Type().HasAttribute(name) {
+			return cty.GetAttrPath(name)
+		}
+	}
+	return nil
+}
+
+func camelToSnake(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				builder.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+func firstSubmatch(pattern *regexp.Regexp, text string) string {
+	if match := pattern.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func stringField(payload map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch value := payload[key].(type) {
+		case string:
+			if value != "" {
+				return value
+			}
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+	return ""
+}
@@ -2,8 +2,10 @@ package common
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"hash/crc32"
+	"time"
 )
 
 // Generates a hash for the set hash function used by the IDs
@@ -32,3 +34,27 @@ func HashString(s string) int {
 	// v == MinInt
 	return 0
 }
+
+// SleepContext pauses for d, or until ctx is cancelled, in which case it returns
+// the context's error.
+func SleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IndexOfId returns the index of the first of n items whose id, as returned by
+// itemId, equals id, or -1 when there is none. Items without an id are skipped.
+func IndexOfId(n int, id string, itemId func(i int) *string) int {
+	for i := 0; i < n; i++ {
+		if value := itemId(i); value != nil && *value == id {
+			return i
+		}
+	}
+	return -1
+}
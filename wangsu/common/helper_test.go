@@ -0,0 +1,28 @@
+package common
+
+import "testing"
+
+func TestIndexOfId(t *testing.T) {
+	id := func(s string) *string { return &s }
+	cases := []struct {
+		name string
+		ids  []*string
+		id   string
+		want int
+	}{
+		{"empty", nil, "1", -1},
+		{"found", []*string{id("1"), id("2")}, "2", 1},
+		{"first match", []*string{id("2"), id("2")}, "2", 0},
+		{"nil id skipped", []*string{nil, id("2")}, "2", 1},
+		{"only nil ids", []*string{nil, nil}, "", -1},
+		{"missing", []*string{id("1")}, "3", -1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := IndexOfId(len(c.ids), c.id, func(i int) *string { return c.ids[i] })
+			if got != c.want {
+				t.Errorf("IndexOfId(%q) = %d, want %d", c.id, got, c.want)
+			}
+		})
+	}
+}
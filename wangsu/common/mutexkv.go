@@ -0,0 +1,99 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MutexKV is a set of mutexes addressed by key, so that operations on the same key
+// run one at a time while operations on different keys run in parallel.
+type MutexKV struct {
+	lock  sync.Mutex
+	store map[string]chan struct{}
+}
+
+func NewMutexKV() *MutexKV {
+	return &MutexKV{store: make(map[string]chan struct{})}
+}
+
+// Lock acquires the mutex of key, creating it on first use. It gives up once ctx
+// is done, e.g. when the timeout of an operation expires while another one holds
+// the key.
+func (m *MutexKV) Lock(ctx context.Context, key string) error {
+	log.Printf("[DEBUG] Locking %q", key)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("waiting for other resources to finish writing %s: %w", key, err)
+	}
+	select {
+	case m.get(key) <- struct{}{}:
+		log.Printf("[DEBUG] Locked %q", key)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for other resources to finish writing %s: %w", key, ctx.Err())
+	}
+}
+
+// Unlock releases the mutex of key.
+func (m *MutexKV) Unlock(key string) {
+	log.Printf("[DEBUG] Unlocking %q", key)
+	<-m.get(key)
+	log.Printf("[DEBUG] Unlocked %q", key)
+}
+
+func (m *MutexKV) get(key string) chan struct{} {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	mutex, ok := m.store[key]
+	if !ok {
+		mutex = make(chan struct{}, 1)
+		m.store[key] = mutex
+	}
+	return mutex
+}
+
+// domainMutexKV serialises writes and deployments on the same hostname across all
+// resources of the provider, the API rejects overlapping deployments of a domain.
+var domainMutexKV = NewMutexKV()
+
+// LockDomain blocks until no other resource is writing to domain, or until ctx is
+// done, and returns the function that releases it.
+func LockDomain(ctx context.Context, domain string) (unlock func(), err error) {
+	return LockDomains(ctx, []interface{}{domain})
+}
+
+// LockDomains locks every domain of a Terraform list of strings. They are locked in
+// sorted order so that two resources sharing some of their domains cannot deadlock.
+// When ctx is done first, the domains locked so far are released again.
+func LockDomains(ctx context.Context, domains []interface{}) (unlock func(), err error) {
+	keys := make([]string, 0, len(domains))
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		key, _ := domain.(string)
+		key = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(key)), ".")
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	unlockFirst := func(n int) {
+		for i := n - 1; i >= 0; i-- {
+			domainMutexKV.Unlock(keys[i])
+		}
+	}
+	for i, key := range keys {
+		if err := domainMutexKV.Lock(ctx, key); err != nil {
+			unlockFirst(i)
+			return nil, err
+		}
+	}
+	return func() {
+		unlockFirst(len(keys))
+	}, nil
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLockDomainsGivesUpWithContext(t *testing.T) {
+	unlock, err := LockDomain(context.Background(), "b.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := LockDomains(ctx, []interface{}{"a.example.com", "B.example.com."}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// a.example.com was locked before the wait and must have been released
+	unlockA, err := LockDomain(context.Background(), "a.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockA()
+
+	unlock()
+	unlockB, err := LockDomain(ctx, "b.example.com")
+	if err == nil {
+		unlockB()
+		t.Fatal("LockDomain succeeded with a done context")
+	}
+	unlockB, err = LockDomain(context.Background(), "b.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockB()
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// IsNotFound reports whether err says that the object a read API addresses in
+// its URL path, such as a domain name or a certificate id, does not exist: an API
+// error with HTTP status 404 (RFC 9110, section 15.5.5) and an error code. Only
+// the reads that take the object in the path may call it; for the APIs that
+// search a list or take the object in the request body, a 404 means a wrong
+// endpoint, and their Read functions tell a deleted object from an empty
+// result instead. A 404 without an error code, such as one from a proxy, is not
+// an API error and fails the read.
+//
+// No error code is matched on its own. A code belongs here only with a link to
+// the page of the API documentation that names it for a missing object.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(NewAPIError(err, ""), &apiErr) {
+		return false
+	}
+	return apiErr.HttpStatus == http.StatusNotFound && apiErr.Code != ""
+}
+
+// RemoveFromState drops a resource whose object was deleted outside Terraform,
+// e.g. in the console, so that the next apply creates it again instead of every
+// plan failing on it. Read functions return its warning.
+func RemoveFromState(data *schema.ResourceData, resourceType string) diag.Diagnostics {
+	id := data.Id()
+	log.Printf("[WARN] %s %s not found, removing it from state", resourceType, id)
+	data.SetId("")
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("%s %s no longer exists", resourceType, id),
+		Detail:   "The object was not found, it was probably deleted outside Terraform. It has been removed from the state and will be created again on the next apply.",
+	}}
+}
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsNotFound(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"api 404", &APIError{Code: "ResourceNotFound", HttpStatus: 404}, true},
+		{"wrapped", fmt.Errorf("read failed: %w", &APIError{Code: "ResourceNotFound", HttpStatus: 404}), true},
+		{"parsed from sdk error", errors.New(`http status 404: {"code":"ResourceNotFound","message":"certificate 123 does not exist","requestId":"r-1"}`), true},
+		{"404 without a code", &APIError{HttpStatus: 404, RequestId: "r-1"}, false},
+		{"proxy 404", errors.New("status code 404: Not Found"), false},
+		{"not-found code with another status", &APIError{Code: "WAAP.DomainNotExist", HttpStatus: 400}, false},
+		{"missing credentials", &APIError{Code: "AccessKeyNotFound", HttpStatus: 401}, false},
+		{"other code", &APIError{Code: "InvalidParameter", HttpStatus: 400}, false},
+		{"network error", errors.New("dial tcp: connection refused"), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := IsNotFound(c.err); got != c.want {
+				t.Errorf("IsNotFound(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
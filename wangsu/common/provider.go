@@ -1,6 +1,10 @@
 package common
 
 import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 )
 
@@ -9,3 +13,53 @@ type ProviderMeta interface {
 	// GetAPIV3Conn 返回访问云 API 的客户端连接对象
 	GetAPIV3Conn() *connectivity.WangSuClient
 }
+
+// ServiceTypeSchema is the service_type argument of the security resources, it
+// overrides the provider's service_type for the calls of one resource.
+func ServiceTypeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Security service type of the API calls, overriding the provider's `service_type`. Set it when the account purchased several security services.",
+	}
+}
+
+// APIConn returns the client for the calls of a resource, honouring its
+// service_type argument when it has one.
+func APIConn(data *schema.ResourceData, meta interface{}) *connectivity.WangSuClient {
+	conn := meta.(ProviderMeta).GetAPIV3Conn()
+	if data == nil {
+		return conn
+	}
+	if serviceType, ok := data.GetOk("service_type"); ok {
+		return conn.WithServiceType(serviceType.(string))
+	}
+	return conn
+}
+
+// AddResourceTypes wraps the functions of every resource or data source in
+// resources, keyed by type, so that their context names the type for the
+// User-Agent of their requests, see connectivity.WithResourceType.
+func AddResourceTypes(resources map[string]*schema.Resource) {
+	for resourceType, r := range resources {
+		r.CreateContext = withResourceType(resourceType, r.CreateContext)
+		r.ReadContext = withResourceType(resourceType, r.ReadContext)
+		r.UpdateContext = withResourceType(resourceType, r.UpdateContext)
+		r.DeleteContext = withResourceType(resourceType, r.DeleteContext)
+		if r.Importer != nil && r.Importer.StateContext != nil {
+			importState := r.Importer.StateContext
+			r.Importer.StateContext = func(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+				return importState(connectivity.WithResourceType(ctx, resourceType), data, meta)
+			}
+		}
+	}
+}
+
+func withResourceType(resourceType string, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		return f(connectivity.WithResourceType(ctx, resourceType), data, meta)
+	}
+}
@@ -0,0 +1,118 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultWaitMinInterval = 2 * time.Second
+	defaultWaitMaxInterval = 30 * time.Second
+)
+
+// DeployRefreshFunc queries the status of a deployment. ctx is done once the
+// wait times out or is cancelled. reason explains a failed status when the API
+// returns one.
+type DeployRefreshFunc func(ctx context.Context) (status string, reason string, err error)
+
+// DeployWaiter polls a deployment until it reaches one of the Target statuses,
+// in the spirit of resource.StateChangeConf. A Failure status ends the wait at
+// once with a *DeployFailedError. When Pending is empty every status that is
+// neither a target nor a failure is treated as pending; otherwise any status
+// outside the three lists is an error.
+type DeployWaiter struct {
+	Pending []string
+	Target  []string
+	Failure []string
+	Refresh DeployRefreshFunc
+	// Delay is waited before the first query, deployments are rarely visible at once.
+	Delay time.Duration
+	// The interval between queries starts at MinInterval and doubles up to MaxInterval.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	Timeout     time.Duration
+	// Description names what is deployed in log lines and errors.
+	Description string
+}
+
+// DeployFailedError reports a deployment that reached a failure status.
+type DeployFailedError struct {
+	Description string
+	Status      string
+	Reason      string
+}
+
+func (e *DeployFailedError) Error() string {
+	message := fmt.Sprintf("%s failed with status %s", e.Description, e.Status)
+	if e.Reason != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Reason)
+	}
+	return message
+}
+
+// WaitForStatus blocks until the deployment reaches a target status, fails, the
+// timeout expires or ctx is cancelled. It returns the last status seen.
+func (w *DeployWaiter) WaitForStatus(ctx context.Context) (string, error) {
+	minInterval, maxInterval := w.MinInterval, w.MaxInterval
+	if minInterval <= 0 {
+		minInterval = defaultWaitMinInterval
+	}
+	if maxInterval < minInterval {
+		maxInterval = defaultWaitMaxInterval
+		if maxInterval < minInterval {
+			maxInterval = minInterval
+		}
+	}
+	description := w.Description
+	if description == "" {
+		description = "deployment"
+	}
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	if w.Delay > 0 {
+		if err := SleepContext(ctx, w.Delay); err != nil {
+			return "", w.waitError(ctx, description, "", err)
+		}
+	}
+
+	interval := minInterval
+	for {
+		status, reason, err := w.Refresh(ctx)
+		if err != nil {
+			return status, w.waitError(ctx, description, status, err)
+		}
+		switch {
+		case IsContains(w.Target, status):
+			log.Printf("[DEBUG] %s reached status %s", description, status)
+			return status, nil
+		case IsContains(w.Failure, status):
+			return status, &DeployFailedError{Description: description, Status: status, Reason: reason}
+		case len(w.Pending) > 0 && !IsContains(w.Pending, status):
+			return status, fmt.Errorf("%s reached unexpected status %q, expected one of %v", description, status, w.Target)
+		}
+
+		log.Printf("[DEBUG] %s is %s, checking again in %s", description, status, interval)
+		if err := SleepContext(ctx, interval); err != nil {
+			return status, w.waitError(ctx, description, status, err)
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func (w *DeployWaiter) waitError(ctx context.Context, description, status string, err error) error {
+	if ctx.Err() == context.DeadlineExceeded && w.Timeout > 0 {
+		if status == "" {
+			return fmt.Errorf("timeout after %s while waiting for %s", w.Timeout, description)
+		}
+		return fmt.Errorf("timeout after %s while waiting for %s, last status %s", w.Timeout, description, status)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForStatusRefreshesWithTheTimeout(t *testing.T) {
+	waiter := &DeployWaiter{
+		Target:  []string{"SUCCESS"},
+		Timeout: time.Minute,
+		Refresh: func(ctx context.Context) (string, string, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok || time.Until(deadline) > time.Minute {
+				t.Errorf("refresh got deadline %v (set: %v), want the waiter's timeout", deadline, ok)
+			}
+			return "SUCCESS", "", nil
+		},
+	}
+	if _, err := waiter.WaitForStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForStatusReportsARefreshCutShortAsTimeout(t *testing.T) {
+	waiter := &DeployWaiter{
+		Target:      []string{"SUCCESS"},
+		Timeout:     50 * time.Millisecond,
+		Description: "deployment of request r-1",
+		Refresh: func(ctx context.Context) (string, string, error) {
+			<-ctx.Done()
+			return "", "", ctx.Err()
+		},
+	}
+	_, err := waiter.WaitForStatus(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timeout after 50ms while waiting for deployment of request r-1") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+}
+
+func TestWaitForStatusFails(t *testing.T) {
+	waiter := &DeployWaiter{
+		Target:  []string{"SUCCESS"},
+		Failure: []string{"FAIL"},
+		Refresh: func(context.Context) (string, string, error) {
+			return "FAIL", "origin unreachable", nil
+		},
+	}
+	_, err := waiter.WaitForStatus(context.Background())
+	var failed *DeployFailedError
+	if !errors.As(err, &failed) || failed.Reason != "origin unreachable" {
+		t.Errorf("err = %v, want a *DeployFailedError with the reason", err)
+	}
+}
@@ -0,0 +1,76 @@
+package connectivity
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Call makes one SDK call of service, a key of EndpointServices, for the
+// operation of ctx. The SDK methods take no context and the SDK clients accept no
+// http.Client, so the provider's policies apply around each call instead of to
+// its requests: Call takes a token from the service's rate limiter, logs the call
+// to the service's tflog subsystem and, when the call failed before the API
+// processed it, backs off and calls again, see RetryPolicy. Every attempt takes a
+// token, so retries are metered like first attempts.
+//
+// Call stops waiting once ctx is done or Terraform stops the provider. A call in
+// flight cannot be interrupted; it is waited for, so that an object it creates
+// is not lost.
+//
+// action names the call in the logs, e.g. the SDK method. call returns the
+// request id and the error of the SDK method.
+func (me *WangSuClient) Call(ctx context.Context, service, action string, call func() (requestId string, err error)) error {
+	if err := me.stopContext().Err(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(me.stopContext(), cancel)()
+
+	policy := me.RetryPolicy.withDefaults()
+	ctx = tflog.NewSubsystem(ctx, service)
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := me.limiter(service).Wait(ctx); err != nil {
+			return err
+		}
+
+		start := time.Now()
+		requestId, err := call()
+		fields := map[string]interface{}{
+			"action":     action,
+			"request_id": requestId,
+			"latency_ms": time.Since(start).Milliseconds(),
+		}
+		if err == nil {
+			tflog.SubsystemDebug(ctx, service, "API call succeeded", fields)
+			return nil
+		}
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, service, "API call failed", fields)
+
+		retry, reason := retryable(err)
+		if !retry || attempt >= policy.MaxRetries {
+			return err
+		}
+		wait := policy.backoff(attempt)
+		tflog.SubsystemDebug(ctx, service, "Retrying API call", map[string]interface{}{
+			"action":      action,
+			"reason":      reason,
+			"wait_ms":     wait.Milliseconds(),
+			"retry":       attempt + 1,
+			"max_retries": policy.MaxRetries,
+		})
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
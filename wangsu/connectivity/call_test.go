@@ -0,0 +1,139 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// testStatusError is a failed call with an HTTP status.
+type testStatusError int
+
+func (e testStatusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e testStatusError) StatusCode() int { return int(e) }
+
+func TestCallRetries(t *testing.T) {
+	cases := map[string]struct {
+		err          error
+		wantAttempts int
+	}{
+		"throttled":           {err: testStatusError(http.StatusTooManyRequests), wantAttempts: 2},
+		"unavailable":         {err: testStatusError(http.StatusServiceUnavailable), wantAttempts: 2},
+		"wrapped":             {err: fmt.Errorf("add rule: %w", testStatusError(http.StatusTooManyRequests)), wantAttempts: 2},
+		"connection refused":  {err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, wantAttempts: 2},
+		"internal error":      {err: testStatusError(http.StatusInternalServerError), wantAttempts: 1},
+		"bad gateway":         {err: testStatusError(http.StatusBadGateway), wantAttempts: 1},
+		"gateway timeout":     {err: testStatusError(http.StatusGatewayTimeout), wantAttempts: 1},
+		"bad request":         {err: testStatusError(http.StatusBadRequest), wantAttempts: 1},
+		"connection reset":    {err: &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, wantAttempts: 1},
+		"without a status":    {err: errors.New("[InvalidParameter] domainName is invalid"), wantAttempts: 1},
+		"cancelled operation": {err: context.Canceled, wantAttempts: 1},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := &WangSuClient{RetryPolicy: RetryPolicy{MaxRetries: 2, MaxWait: retryMinWait}}
+			attempts := 0
+			err := client.Call(context.Background(), EndpointCdn, "Test", func() (string, error) {
+				attempts++
+				if attempts == 1 {
+					return "r-1", tc.err
+				}
+				return "r-2", nil
+			})
+			if attempts != tc.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tc.wantAttempts)
+			}
+			if wantErr := tc.wantAttempts == 1; (err != nil) != wantErr {
+				t.Errorf("err = %v, want an error: %v", err, wantErr)
+			}
+		})
+	}
+}
+
+func TestCallGivesUpAfterMaxRetries(t *testing.T) {
+	client := &WangSuClient{RetryPolicy: RetryPolicy{MaxRetries: 1, MaxWait: retryMinWait}}
+	attempts := 0
+	err := client.Call(context.Background(), EndpointCdn, "Test", func() (string, error) {
+		attempts++
+		return "", testStatusError(http.StatusTooManyRequests)
+	})
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+	if !errors.Is(err, testStatusError(http.StatusTooManyRequests)) {
+		t.Errorf("err = %v, want the error of the last attempt", err)
+	}
+}
+
+func TestCallStops(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	cases := map[string]struct {
+		ctx    context.Context
+		client *WangSuClient
+	}{
+		"operation cancelled": {ctx: cancelled, client: &WangSuClient{}},
+		"provider stopped":    {ctx: context.Background(), client: &WangSuClient{StopContext: cancelled}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.client.Call(tc.ctx, EndpointCdn, "Test", func() (string, error) {
+				t.Error("the call was made")
+				return "", nil
+			})
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("err = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestCallMetersEveryAttempt(t *testing.T) {
+	client := &WangSuClient{
+		RetryPolicy: RetryPolicy{MaxRetries: 1, MaxWait: retryMinWait},
+		// a rate slow enough for the bucket not to refill during the test
+		RateLimit: RateLimit{RequestsPerSecond: 0.001, Burst: 10},
+		ServiceRateLimits: map[string]RateLimit{
+			EndpointWaap: {RequestsPerSecond: 0.001, Burst: 10},
+		},
+	}
+	_ = client.Call(context.Background(), EndpointCdn, "Test", func() (string, error) {
+		return "", testStatusError(http.StatusTooManyRequests)
+	})
+	if tokens := client.limiter(EndpointCdn).tokens; tokens > 8.01 {
+		t.Errorf("shared bucket holds %.2f tokens after 2 attempts, want 8", tokens)
+	}
+	if tokens := client.limiter(EndpointWaap).tokens; tokens != 10 {
+		t.Errorf("waap bucket holds %.2f tokens, want 10", tokens)
+	}
+}
+
+func TestCallLogs(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	client := &WangSuClient{}
+	err := client.Call(ctx, EndpointWaap, "AddWaapWhitelistRule", func() (string, error) {
+		return "r-1", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1: %v", len(entries), entries)
+	}
+	entry := entries[0]
+	if entry["@module"] != "provider.waap" || entry["action"] != "AddWaapWhitelistRule" || entry["request_id"] != "r-1" {
+		t.Errorf("log entry = %v", entry)
+	}
+}
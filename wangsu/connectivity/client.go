@@ -1,6 +1,11 @@
 package connectivity
 
 import (
+	"context"
+	"net/http"
+	"sync"
+
+	wangsuHttp "github.com/wangsu-api/terraform-provider-wangsu/wangsu/http"
 	appadomain "github.com/wangsu-api/wangsu-sdk-go/wangsu/appa/domain"
 	cdn "github.com/wangsu-api/wangsu-sdk-go/wangsu/cdn/domain"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/certificateapplication"
@@ -28,256 +33,259 @@ import (
 
 type WangSuClient struct {
 	Credential  *common.Credential
-	HttpProfile *common.HttpProfile
-
-	cdnConn                       *cdn.Client
-	appaDomainConn                *appadomain.Client
-	sslCertificateConn            *certificate.Client
-	sslCertificateApplicationConn *certificateapplication.Client
-	waapWhitelistConn             *waapWhitelist.Client
-	waapCustomizeruleConn         *waapCustomizerule.Client
-	waapRatelimitConn             *waapRatelimit.Client
-	waapDomainConn                *waapDomain.Client
-	waapShareWhitelistConn        *waapShareWhitelist.Client
-	waapShareCustomizeruleConn    *waapShareCustomizerule.Client
-	waapWAFConn                   *waapWAF.Client
-	waapBotConn                   *waapBot.Client
-	waapDDoSProtectionConn        *waapDDoSProtection.Client
-	waapPreDeployConn             *waapPreDeploy.Client
-	monitorRuleConn               *monitorRule.Client
-	policyConn                    *policy.Client
-	userManageConn                *userManage.Client
-	propertyConfigConn            *propertyConfig.Client
-	edgeHostnameConn              *edgeHostname.Client
-	securityPolicyConn            *securitypolicy.Client
-	waapBotSceneWhitelistConn     *waapBotSceneWhitelist.Client
-	waapShareCustomizeBotConn     *waapShareCustomizeBot.Client
+	Domain      string
+	Protocol    string
+	ServiceType string
+	// Endpoints maps a service key such as EndpointCdn to the base URL that
+	// replaces Protocol and Domain for that service's clients.
+	Endpoints   map[string]string
+	RetryPolicy RetryPolicy
+	HTTPOptions HTTPOptions
+	RateLimit   RateLimit
+	// ServiceRateLimits gives a service its own limiter instead of RateLimit,
+	// keyed like Endpoints.
+	ServiceRateLimits map[string]RateLimit
+	// UserAgent is the User-Agent of the requests of the clients the provider
+	// builds itself, see UserAgent().
+	UserAgent string
+	// UserAgentSuffix follows UserAgent and the resource type of the request.
+	UserAgentSuffix string
+	// StopContext is cancelled when Terraform interrupts the run, it stops the
+	// waits of Call and aborts the requests of the clients the provider builds.
+	StopContext context.Context
+
+	limitersOnce    sync.Once
+	sharedLimiter   *tokenBucket
+	serviceLimiters map[string]*tokenBucket
+
+	transportOnce sync.Once
+	transport     http.RoundTripper
+	transportErr  error
+
+	// parent is the provider's client when this one was derived from it by
+	// WithServiceType; derived clients share its rate limiters and transport.
+	parent             *WangSuClient
+	serviceTypeClients sync.Map
+
+	cdnConn                       lazyClient[cdn.Client]
+	appaDomainConn                lazyClient[appadomain.Client]
+	sslCertificateConn            lazyClient[certificate.Client]
+	sslCertificateApplicationConn lazyClient[certificateapplication.Client]
+	waapWhitelistConn             lazyClient[waapWhitelist.Client]
+	waapCustomizeruleConn         lazyClient[waapCustomizerule.Client]
+	waapRatelimitConn             lazyClient[waapRatelimit.Client]
+	waapDomainConn                lazyClient[waapDomain.Client]
+	waapShareWhitelistConn        lazyClient[waapShareWhitelist.Client]
+	waapShareCustomizeruleConn    lazyClient[waapShareCustomizerule.Client]
+	waapWAFConn                   lazyClient[waapWAF.Client]
+	waapBotConn                   lazyClient[waapBot.Client]
+	waapDDoSProtectionConn        lazyClient[waapDDoSProtection.Client]
+	waapPreDeployConn             lazyClient[waapPreDeploy.Client]
+	monitorRuleConn               lazyClient[monitorRule.Client]
+	policyConn                    lazyClient[policy.Client]
+	userManageConn                lazyClient[userManage.Client]
+	propertyConfigConn            lazyClient[propertyConfig.Client]
+	edgeHostnameConn              lazyClient[edgeHostname.Client]
+	securityPolicyConn            lazyClient[securitypolicy.Client]
+	waapBotSceneWhitelistConn     lazyClient[waapBotSceneWhitelist.Client]
+	waapShareCustomizeBotConn     lazyClient[waapShareCustomizeBot.Client]
 }
 
-func (me *WangSuClient) UseCdnClient() *cdn.Client {
-	if me.cdnConn != nil {
-		return me.cdnConn
-	}
-
-	me.cdnConn, _ = cdn.NewClient(me.Credential, me.HttpProfile)
-
-	return me.cdnConn
+func (me *WangSuClient) UseCdnClient() (*cdn.Client, error) {
+	return useClient(me, &me.cdnConn, EndpointCdn, func(httpProfile *common.HttpProfile) (*cdn.Client, error) {
+		return cdn.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseAppaDomainClient() *appadomain.Client {
-	if me.appaDomainConn != nil {
-		return me.appaDomainConn
-	}
-
-	me.appaDomainConn, _ = appadomain.NewClient(me.Credential, me.HttpProfile)
-
-	return me.appaDomainConn
+func (me *WangSuClient) UseAppaDomainClient() (*appadomain.Client, error) {
+	return useClient(me, &me.appaDomainConn, EndpointAppa, func(httpProfile *common.HttpProfile) (*appadomain.Client, error) {
+		return appadomain.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapWhitelistClient() *waapWhitelist.Client {
-	if me.waapWhitelistConn != nil {
-		return me.waapWhitelistConn
-	}
-
-	me.waapWhitelistConn, _ = waapWhitelist.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapWhitelistConn
+func (me *WangSuClient) UseWaapWhitelistClient() (*waapWhitelist.Client, error) {
+	return useClient(me, &me.waapWhitelistConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapWhitelist.Client, error) {
+		return waapWhitelist.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapCustomizeruleClient() *waapCustomizerule.Client {
-	if me.waapCustomizeruleConn != nil {
-		return me.waapCustomizeruleConn
-	}
-
-	me.waapCustomizeruleConn, _ = waapCustomizerule.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapCustomizeruleConn
+func (me *WangSuClient) UseWaapCustomizeruleClient() (*waapCustomizerule.Client, error) {
+	return useClient(me, &me.waapCustomizeruleConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapCustomizerule.Client, error) {
+		return waapCustomizerule.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapRatelimitClient() *waapRatelimit.Client {
-	if me.waapRatelimitConn != nil {
-		return me.waapRatelimitConn
-	}
-
-	me.waapRatelimitConn, _ = waapRatelimit.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapRatelimitConn
+func (me *WangSuClient) UseWaapRatelimitClient() (*waapRatelimit.Client, error) {
+	return useClient(me, &me.waapRatelimitConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapRatelimit.Client, error) {
+		return waapRatelimit.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapDomainClient() *waapDomain.Client {
-	if me.waapDomainConn != nil {
-		return me.waapDomainConn
-	}
-
-	me.waapDomainConn, _ = waapDomain.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapDomainConn
+func (me *WangSuClient) UseWaapDomainClient() (*waapDomain.Client, error) {
+	return useClient(me, &me.waapDomainConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapDomain.Client, error) {
+		return waapDomain.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapShareWhitelistClient() *waapShareWhitelist.Client {
-	if me.waapShareWhitelistConn != nil {
-		return me.waapShareWhitelistConn
-	}
-
-	me.waapShareWhitelistConn, _ = waapShareWhitelist.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapShareWhitelistConn
+func (me *WangSuClient) UseWaapShareWhitelistClient() (*waapShareWhitelist.Client, error) {
+	return useClient(me, &me.waapShareWhitelistConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapShareWhitelist.Client, error) {
+		return waapShareWhitelist.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapShareCustomizeruleClient() *waapShareCustomizerule.Client {
-	if me.waapShareCustomizeruleConn != nil {
-		return me.waapShareCustomizeruleConn
-	}
-
-	me.waapShareCustomizeruleConn, _ = waapShareCustomizerule.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapShareCustomizeruleConn
+func (me *WangSuClient) UseWaapShareCustomizeruleClient() (*waapShareCustomizerule.Client, error) {
+	return useClient(me, &me.waapShareCustomizeruleConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapShareCustomizerule.Client, error) {
+		return waapShareCustomizerule.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapBotSceneWhiteListClient() *waapBotSceneWhitelist.Client {
-	if me.waapBotConn != nil {
-		return me.waapBotSceneWhitelistConn
-	}
-
-	me.waapBotSceneWhitelistConn, _ = waapBotSceneWhitelist.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapBotSceneWhitelistConn
+func (me *WangSuClient) UseWaapBotSceneWhiteListClient() (*waapBotSceneWhitelist.Client, error) {
+	return useClient(me, &me.waapBotSceneWhitelistConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapBotSceneWhitelist.Client, error) {
+		return waapBotSceneWhitelist.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapShareCustomizeBotClient() *waapShareCustomizeBot.Client {
-	if me.waapShareCustomizeBotConn != nil {
-		return me.waapShareCustomizeBotConn
-	}
-
-	me.waapShareCustomizeBotConn, _ = waapShareCustomizeBot.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapShareCustomizeBotConn
+func (me *WangSuClient) UseWaapShareCustomizeBotClient() (*waapShareCustomizeBot.Client, error) {
+	return useClient(me, &me.waapShareCustomizeBotConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapShareCustomizeBot.Client, error) {
+		return waapShareCustomizeBot.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapPreDeployClient() *waapPreDeploy.Client {
-	if me.waapPreDeployConn != nil {
-		return me.waapPreDeployConn
-	}
-
-	me.waapPreDeployConn, _ = waapPreDeploy.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapPreDeployConn
+func (me *WangSuClient) UseWaapPreDeployClient() (*waapPreDeploy.Client, error) {
+	return useClient(me, &me.waapPreDeployConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapPreDeploy.Client, error) {
+		return waapPreDeploy.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapWAFClient() *waapWAF.Client {
-	if me.waapWAFConn != nil {
-		return me.waapWAFConn
-	}
-
-	me.waapWAFConn, _ = waapWAF.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapWAFConn
+func (me *WangSuClient) UseWaapWAFClient() (*waapWAF.Client, error) {
+	return useClient(me, &me.waapWAFConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapWAF.Client, error) {
+		return waapWAF.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapBotClient() *waapBot.Client {
-	if me.waapBotConn != nil {
-		return me.waapBotConn
-	}
-
-	me.waapBotConn, _ = waapBot.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapBotConn
+func (me *WangSuClient) UseWaapBotClient() (*waapBot.Client, error) {
+	return useClient(me, &me.waapBotConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapBot.Client, error) {
+		return waapBot.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseWaapDDoSProtectionClient() *waapDDoSProtection.Client {
-	if me.waapDDoSProtectionConn != nil {
-		return me.waapDDoSProtectionConn
-	}
-
-	me.waapDDoSProtectionConn, _ = waapDDoSProtection.NewClient(me.Credential, me.HttpProfile)
-
-	return me.waapDDoSProtectionConn
+func (me *WangSuClient) UseWaapDDoSProtectionClient() (*waapDDoSProtection.Client, error) {
+	return useClient(me, &me.waapDDoSProtectionConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*waapDDoSProtection.Client, error) {
+		return waapDDoSProtection.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseSecurityPolicyClient() *securitypolicy.Client {
-	if me.securityPolicyConn != nil {
-		return me.securityPolicyConn
-	}
-
-	me.securityPolicyConn, _ = securitypolicy.NewClient(me.Credential, me.HttpProfile)
-
-	return me.securityPolicyConn
+func (me *WangSuClient) UseSecurityPolicyClient() (*securitypolicy.Client, error) {
+	return useClient(me, &me.securityPolicyConn, EndpointWaap, func(httpProfile *common.HttpProfile) (*securitypolicy.Client, error) {
+		return securitypolicy.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseSslCertificateClient() *certificate.Client {
-	if me.sslCertificateConn != nil {
-		return me.sslCertificateConn
-	}
-
-	me.sslCertificateConn, _ = certificate.NewClient(me.Credential, me.HttpProfile)
-
-	return me.sslCertificateConn
+func (me *WangSuClient) UseSslCertificateClient() (*certificate.Client, error) {
+	return useClient(me, &me.sslCertificateConn, EndpointSsl, func(httpProfile *common.HttpProfile) (*certificate.Client, error) {
+		return certificate.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseSslCertificateApplicationClient() *certificateapplication.Client {
-	if me.sslCertificateApplicationConn != nil {
-		return me.sslCertificateApplicationConn
-	}
-
-	me.sslCertificateApplicationConn, _ = certificateapplication.NewClient(me.Credential, me.HttpProfile)
-
-	return me.sslCertificateApplicationConn
+func (me *WangSuClient) UseSslCertificateApplicationClient() (*certificateapplication.Client, error) {
+	return useClient(me, &me.sslCertificateApplicationConn, EndpointCertificateApplication, func(httpProfile *common.HttpProfile) (*certificateapplication.Client, error) {
+		return certificateapplication.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UseMonitorRuleClient() *monitorRule.Client {
-	if me.monitorRuleConn != nil {
-		return me.monitorRuleConn
-	}
-
-	me.monitorRuleConn, _ = monitorRule.NewClient(me.Credential, me.HttpProfile)
-
-	return me.monitorRuleConn
+func (me *WangSuClient) UseMonitorRuleClient() (*monitorRule.Client, error) {
+	return useClient(me, &me.monitorRuleConn, EndpointMonitor, func(httpProfile *common.HttpProfile) (*monitorRule.Client, error) {
+		return monitorRule.NewClient(me.Credential, httpProfile)
+	})
 }
-func (me *WangSuClient) UseUserManageClient() *userManage.Client {
-	if me.userManageConn != nil {
-		return me.userManageConn
-	}
 
-	me.userManageConn, _ = userManage.NewClient(me.Credential, me.HttpProfile)
+func (me *WangSuClient) UseUserManageClient() (*userManage.Client, error) {
+	return useClient(me, &me.userManageConn, EndpointIam, func(httpProfile *common.HttpProfile) (*userManage.Client, error) {
+		return userManage.NewClient(me.Credential, httpProfile)
+	})
+}
 
-	return me.userManageConn
+func (me *WangSuClient) UsePolicyClient() (*policy.Client, error) {
+	return useClient(me, &me.policyConn, EndpointIam, func(httpProfile *common.HttpProfile) (*policy.Client, error) {
+		return policy.NewClient(me.Credential, httpProfile)
+	})
 }
-func (me *WangSuClient) UsePolicyClient() *policy.Client {
-	if me.policyConn != nil {
-		return me.policyConn
-	}
 
-	me.policyConn, _ = policy.NewClient(me.Credential, me.HttpProfile)
+func (me *WangSuClient) UsePolicyAttachmentClient() (*userManage.Client, error) {
+	return useClient(me, &me.userManageConn, EndpointIam, func(httpProfile *common.HttpProfile) (*userManage.Client, error) {
+		return userManage.NewClient(me.Credential, httpProfile)
+	})
+}
 
-	return me.policyConn
+func (me *WangSuClient) UsePropertyConfigClient() (*propertyConfig.Client, error) {
+	return useClient(me, &me.propertyConfigConn, EndpointPropertyConfig, func(httpProfile *common.HttpProfile) (*propertyConfig.Client, error) {
+		return propertyConfig.NewClient(me.Credential, httpProfile)
+	})
 }
 
-func (me *WangSuClient) UsePolicyAttachmentClient() *userManage.Client {
-	if me.userManageConn != nil {
-		return me.userManageConn
-	}
+func (me *WangSuClient) UseEdgeHostnameClient() (*edgeHostname.Client, error) {
+	return useClient(me, &me.edgeHostnameConn, EndpointEdgeHostname, func(httpProfile *common.HttpProfile) (*edgeHostname.Client, error) {
+		return edgeHostname.NewClient(me.Credential, httpProfile)
+	})
+}
 
-	me.userManageConn, _ = userManage.NewClient(me.Credential, me.HttpProfile)
+// UseContentClient returns a client of the content purge and prefetch APIs, which
+// the SDK has no client for. It signs its requests itself and sends them through
+// the client's chain, see HTTPTransport.
+func (me *WangSuClient) UseContentClient() (*wangsuHttp.Client, error) {
+	return me.signedClient(EndpointCdn)
+}
 
-	return me.userManageConn
+// UseCdnDomainConfigClient returns a client of the CDN domain API for the
+// domain configuration that the SDK's domain types do not carry, such as the
+// visit control rules. It signs its requests like UseContentClient's.
+func (me *WangSuClient) UseCdnDomainConfigClient() (*wangsuHttp.Client, error) {
+	return me.signedClient(EndpointCdn)
 }
 
-func (me *WangSuClient) UsePropertyConfigClient() *propertyConfig.Client {
-	if me.propertyConfigConn != nil {
-		return me.propertyConfigConn
+// signedClient returns a client of the service's API that signs its requests
+// with the provider's access key.
+func (me *WangSuClient) signedClient(service string) (*wangsuHttp.Client, error) {
+	httpProfile, err := me.httpProfile(service)
+	if err != nil {
+		return nil, err
 	}
-
-	me.propertyConfigConn, _ = propertyConfig.NewClient(me.Credential, me.HttpProfile)
-
-	return me.propertyConfigConn
+	protocol := httpProfile.Protocol
+	if protocol == "" {
+		protocol = "https"
+	}
+	return &wangsuHttp.Client{
+		SecretId:   me.Credential.SecretId,
+		SecretKey:  me.Credential.SecretKey,
+		BaseURL:    protocol + "://" + me.APIDomain(service),
+		HTTPClient: me.httpClient(service),
+	}, nil
 }
 
-func (me *WangSuClient) UseEdgeHostnameClient() *edgeHostname.Client {
-	if me.edgeHostnameConn != nil {
-		return me.edgeHostnameConn
-	}
+// useClient returns the service's client, built on first use. Its calls go
+// through Call, which rate limits, logs and retries them as the service's.
+func useClient[T any](me *WangSuClient, conn *lazyClient[T], service string, newClient func(*common.HttpProfile) (*T, error)) (*T, error) {
+	return conn.get(func() (*T, error) {
+		httpProfile, err := me.httpProfile(service)
+		if err != nil {
+			return nil, err
+		}
+		return newClient(httpProfile)
+	})
+}
 
-	me.edgeHostnameConn, _ = edgeHostname.NewClient(me.Credential, me.HttpProfile)
+// lazyClient builds an SDK client on first use. Resources run in parallel
+// goroutines, so construction happens exactly once and its error is handed to
+// every caller instead of leaving a nil client behind.
+type lazyClient[T any] struct {
+	once   sync.Once
+	client *T
+	err    error
+}
 
-	return me.edgeHostnameConn
+func (l *lazyClient[T]) get(newClient func() (*T, error)) (*T, error) {
+	l.once.Do(func() {
+		l.client, l.err = newClient()
+	})
+	return l.client, l.err
 }
@@ -0,0 +1,32 @@
+package connectivity
+
+import "context"
+
+type contextKey int
+
+const (
+	serviceContextKey contextKey = iota
+	resourceTypeContextKey
+)
+
+// WithResourceType records the type of the resource or data source whose
+// operation ctx belongs to, e.g. wangsu_cdn_domain. The User-Agent of the
+// operation's requests names it.
+func WithResourceType(ctx context.Context, resourceType string) context.Context {
+	return context.WithValue(ctx, resourceTypeContextKey, resourceType)
+}
+
+func resourceTypeFromContext(ctx context.Context) string {
+	resourceType, _ := ctx.Value(resourceTypeContextKey).(string)
+	return resourceType
+}
+
+// withService records the service a request belongs to, a key of EndpointServices.
+func withService(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, serviceContextKey, service)
+}
+
+func serviceFromContext(ctx context.Context) string {
+	service, _ := ctx.Value(serviceContextKey).(string)
+	return service
+}
@@ -0,0 +1,142 @@
+package connectivity
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DefaultSharedCredentialsFile = "~/.wangsu/credentials"
+	DefaultProfile               = "default"
+)
+
+// SharedProfile is one section of a shared credentials file, for example:
+//
+//	[staging]
+//	secret_id    = my-secret-id
+//	secret_key   = my-secret-key
+//	site         = cn
+//	domain       = open.chinanetcenter.com
+//	protocol     = https
+//	service_type = appa
+type SharedProfile struct {
+	SecretId    string
+	SecretKey   string
+	Site        string
+	Domain      string
+	Protocol    string
+	ServiceType string
+}
+
+// LoadSharedProfile reads the named profile from a shared credentials file. A
+// missing file or profile is only an error when strict is set, i.e. when the user
+// asked for that file or profile explicitly; otherwise nil is returned.
+func LoadSharedProfile(path, profile string, strict bool) (*SharedProfile, error) {
+	if path == "" {
+		path = DefaultSharedCredentialsFile
+	}
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := parseCredentialsFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !strict {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read shared credentials file %s: %w", path, err)
+	}
+	values, ok := sections[profile]
+	if !ok {
+		if !strict {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("profile %q not found in shared credentials file %s", profile, path)
+	}
+
+	sharedProfile := &SharedProfile{}
+	for key, value := range values {
+		switch key {
+		case "secret_id":
+			sharedProfile.SecretId = value
+		case "secret_key":
+			sharedProfile.SecretKey = value
+		case "site":
+			if value != SiteCn && value != SiteIntl {
+				return nil, fmt.Errorf("profile %q in %s: site must be cn or intl, got %q", profile, path, value)
+			}
+			sharedProfile.Site = value
+		case "domain":
+			sharedProfile.Domain = value
+		case "protocol":
+			if value != "http" && value != "https" {
+				return nil, fmt.Errorf("profile %q in %s: protocol must be http or https, got %q", profile, path, value)
+			}
+			sharedProfile.Protocol = value
+		case "service_type":
+			sharedProfile.ServiceType = value
+		default:
+			return nil, fmt.Errorf("profile %q in %s: unknown key %q", profile, path, key)
+		}
+	}
+	return sharedProfile, nil
+}
+
+// parseCredentialsFile parses the small INI subset used by the credentials file:
+// [section] headers, key = value pairs and full-line comments starting with # or ;.
+func parseCredentialsFile(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sections := map[string]map[string]string{}
+	var current map[string]string
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := sections[name]; !ok {
+				sections[name] = map[string]string{}
+			}
+			current = sections[name]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			return nil, fmt.Errorf("line %d: expected [profile] or key = value", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
@@ -0,0 +1,77 @@
+package connectivity
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/wangsu-api/wangsu-sdk-go/wangsu/common"
+)
+
+// Keys of the provider's endpoints block, one per group of SDK clients.
+const (
+	EndpointCdn                    = "cdn"
+	EndpointAppa                   = "appa"
+	EndpointSsl                    = "ssl"
+	EndpointCertificateApplication = "certificate_application"
+	EndpointWaap                   = "waap"
+	EndpointMonitor                = "monitor"
+	EndpointIam                    = "iam"
+	EndpointPropertyConfig         = "property_config"
+	EndpointEdgeHostname           = "edge_hostname"
+)
+
+var EndpointServices = []string{
+	EndpointCdn,
+	EndpointAppa,
+	EndpointSsl,
+	EndpointCertificateApplication,
+	EndpointWaap,
+	EndpointMonitor,
+	EndpointIam,
+	EndpointPropertyConfig,
+	EndpointEdgeHostname,
+}
+
+// ParseEndpoint splits an endpoint override such as https://gateway.example.com:8443
+// into the protocol and domain the SDK expects. The SDK builds request paths on its
+// own, so an endpoint cannot carry a path prefix.
+func ParseEndpoint(endpoint string) (protocol string, domain string, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("invalid endpoint %q: scheme must be http or https", endpoint)
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("invalid endpoint %q: missing host", endpoint)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", "", fmt.Errorf("invalid endpoint %q: only scheme, host and port are supported", endpoint)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// APIDomain returns the domain the requests of a service are sent to.
+func (me *WangSuClient) APIDomain(service string) string {
+	if _, domain, err := ParseEndpoint(me.Endpoints[service]); err == nil {
+		return domain
+	}
+	if me.Domain == "" {
+		return SiteDomain(SiteCn)
+	}
+	return me.Domain
+}
+
+// httpProfile returns the profile for one service, honouring its endpoint override.
+func (me *WangSuClient) httpProfile(service string) (*common.HttpProfile, error) {
+	domain, protocol := me.Domain, me.Protocol
+	if endpoint := me.Endpoints[service]; endpoint != "" {
+		var err error
+		protocol, domain, err = ParseEndpoint(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("endpoints.%s: %w", service, err)
+		}
+	}
+	return common.NewHttpProfile(domain, protocol, me.ServiceType), nil
+}
@@ -0,0 +1,132 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPOptions configures the connections of the clients the provider builds
+// itself, see HTTPTransport; the SDK clients accept no transport and keep their
+// own. The zero value keeps net/http's defaults, including the
+// HTTP_PROXY/HTTPS_PROXY variables.
+type HTTPOptions struct {
+	// CABundleFile is a PEM file of certificates trusted in addition to the
+	// system roots, e.g. the CA of a TLS-intercepting proxy.
+	CABundleFile string
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool
+	// HTTPProxy is the URL of the proxy every request goes through, it replaces
+	// the proxy environment variables.
+	HTTPProxy string
+	// RequestTimeout bounds each request, response body included.
+	// Zero means no limit.
+	RequestTimeout time.Duration
+}
+
+// ParseHTTPProxy checks a proxy URL such as http://proxy.example.com:3128.
+func ParseHTTPProxy(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		return nil, fmt.Errorf("invalid proxy %q: scheme must be http, https or socks5", proxy)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxy)
+	}
+	return u, nil
+}
+
+// wrap returns base configured with the options. base is only cloned when a
+// connection setting differs from the defaults.
+func (o HTTPOptions) wrap(base http.RoundTripper) (http.RoundTripper, error) {
+	if o.CABundleFile != "" || o.InsecureSkipVerify || o.HTTPProxy != "" {
+		transport, ok := base.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("cannot apply TLS and proxy settings to a %T transport", base)
+		}
+		transport = transport.Clone()
+		if err := o.configure(transport); err != nil {
+			return nil, err
+		}
+		base = transport
+	}
+	if o.RequestTimeout > 0 {
+		base = &timeoutTransport{next: base, timeout: o.RequestTimeout}
+	}
+	return base, nil
+}
+
+func (o HTTPOptions) configure(transport *http.Transport) error {
+	if o.CABundleFile != "" || o.InsecureSkipVerify {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = o.InsecureSkipVerify
+	}
+	if o.CABundleFile != "" {
+		pem, err := os.ReadFile(o.CABundleFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CA bundle %s holds no PEM certificate", o.CABundleFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	if o.HTTPProxy != "" {
+		proxy, err := ParseHTTPProxy(o.HTTPProxy)
+		if err != nil {
+			return err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return nil
+}
+
+// timeoutTransport gives each request its own deadline, so that a stalled
+// connection is abandoned.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		// Report the request's own deadline as a timeout rather than as the
+		// end of the caller's context.
+		if errors.Is(err, context.DeadlineExceeded) && req.Context().Err() == nil {
+			return nil, &requestTimeoutError{method: req.Method, path: req.URL.Path, timeout: t.timeout}
+		}
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: cancel}
+	return resp, nil
+}
+
+type requestTimeoutError struct {
+	method, path string
+	timeout      time.Duration
+}
+
+func (e *requestTimeoutError) Error() string {
+	return fmt.Sprintf("%s %s: no response within the request timeout of %s", e.method, e.path, e.timeout)
+}
+
+func (e *requestTimeoutError) Timeout() bool   { return true }
+func (e *requestTimeoutError) Temporary() bool { return true }
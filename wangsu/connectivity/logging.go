@@ -0,0 +1,203 @@
+package connectivity
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// defaultLogSubsystem receives the requests sent without a service, see
+	// withService.
+	defaultLogSubsystem = "api"
+
+	requestIdHeader = "x-cnc-request-id"
+	redacted        = "***"
+)
+
+// sensitiveFields are compared against JSON keys, query parameters and header
+// names after lowercasing and dropping '_' and '-'.
+var sensitiveFields = map[string]bool{
+	"authorization": true,
+	"signature":     true,
+	"secretkey":     true,
+	"privatekey":    true,
+	"dnsapiaccess":  true,
+	"password":      true,
+	"token":         true,
+	"primarykey":    true,
+	"backupkey":     true,
+}
+
+var privateKeyPattern = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// loggingTransport writes every API exchange of the clients the provider builds
+// to the tflog subsystem of its service, e.g. "cdn" or "waap": a summary at DEBUG
+// and the redacted headers and bodies at TRACE. The calls of the SDK clients are
+// logged by Call, which cannot see their requests. Entries are written through the request's context, which
+// carries the logger and the fields of the operation that sent it.
+type loggingTransport struct {
+	next http.RoundTripper
+	// trace is set when provider logs are written at TRACE, bodies are only
+	// read then.
+	trace bool
+}
+
+func newLoggingTransport(next http.RoundTripper) *loggingTransport {
+	return &loggingTransport{next: next, trace: traceEnabled()}
+}
+
+// traceEnabled reports whether TRACE is the level of provider logs. tflog cannot
+// be asked for its level, so the variables it reads the level from are checked.
+func traceEnabled() bool {
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if name != "TF_LOG" && !strings.HasPrefix(name, "TF_LOG_PROVIDER") {
+			continue
+		}
+		if strings.EqualFold(value, "TRACE") || strings.EqualFold(value, "JSON") {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	subsystem := serviceFromContext(req.Context())
+	if subsystem == "" {
+		subsystem = defaultLogSubsystem
+	}
+	ctx := tflog.NewSubsystem(req.Context(), subsystem)
+
+	if t.trace {
+		requestBody, err := peekBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+		tflog.SubsystemTrace(ctx, subsystem, "Sending HTTP request", map[string]interface{}{
+			"http_method":  req.Method,
+			"http_url":     redactURL(req.URL),
+			"http_headers": redactHeaders(req.Header),
+			"http_body":    redactBody(requestBody),
+		})
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields := map[string]interface{}{
+		"http_method":     req.Method,
+		"http_path":       req.URL.Path,
+		"http_latency_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, subsystem, "HTTP request failed", fields)
+		return resp, err
+	}
+	fields["http_status_code"] = resp.StatusCode
+	fields["request_id"] = resp.Header.Get(requestIdHeader)
+	tflog.SubsystemDebug(ctx, subsystem, "Received HTTP response", fields)
+
+	if !t.trace {
+		return resp, nil
+	}
+	responseBody, err := peekBody(&resp.Body)
+	if err != nil {
+		return resp, nil
+	}
+	tflog.SubsystemTrace(ctx, subsystem, "HTTP response", map[string]interface{}{
+		"request_id":   resp.Header.Get(requestIdHeader),
+		"http_headers": redactHeaders(resp.Header),
+		"http_body":    redactBody(responseBody),
+	})
+	return resp, nil
+}
+
+// peekBody reads a body and replaces it with an in-memory copy.
+func peekBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	content, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(content))
+	return content, err
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("_", "", "-", "").Replace(name)
+	if sensitiveFields[name] {
+		return true
+	}
+	// keys of any kind, such as the URL authentication keys of a domain
+	return strings.Contains(name, "secret") || strings.Contains(name, "signature") || strings.HasSuffix(name, "key")
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	redactedHeader := make(map[string]string, len(header))
+	for name, values := range header {
+		if isSensitive(name) {
+			redactedHeader[name] = redacted
+			continue
+		}
+		redactedHeader[name] = strings.Join(values, ", ")
+	}
+	return redactedHeader
+}
+
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	query := u.Query()
+	for name := range query {
+		if isSensitive(name) {
+			query.Set(name, redacted)
+		}
+	}
+	redactedURL := *u
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if redactedBody, err := json.Marshal(redactValue(payload)); err == nil {
+			return string(redactedBody)
+		}
+	}
+	return privateKeyPattern.ReplaceAllString(string(body), redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, item := range v {
+			if isSensitive(name) {
+				v[name] = redacted
+				continue
+			}
+			v[name] = redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	case string:
+		return privateKeyPattern.ReplaceAllString(v, redacted)
+	}
+	return value
+}
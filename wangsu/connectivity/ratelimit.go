@@ -0,0 +1,90 @@
+package connectivity
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit caps the rate at which API requests are sent. A RequestsPerSecond of
+// 0 disables limiting, a Burst of 0 allows one second worth of requests at once.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// tokenBucket is a token-bucket limiter. A nil bucket never blocks.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(limit.RequestsPerSecond))
+	}
+	return &tokenBucket{
+		rate:   limit.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent. Tokens are reserved on entry, so
+// callers are served in the order they arrive.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limiter returns the bucket of a service: its own when the service has an
+// override, the one shared by all other services otherwise.
+func (me *WangSuClient) limiter(service string) *tokenBucket {
+	if me.parent != nil {
+		return me.parent.limiter(service)
+	}
+	me.limitersOnce.Do(func() {
+		me.sharedLimiter = newTokenBucket(me.RateLimit)
+		me.serviceLimiters = make(map[string]*tokenBucket, len(me.ServiceRateLimits))
+		for name, limit := range me.ServiceRateLimits {
+			me.serviceLimiters[name] = newTokenBucket(limit)
+		}
+	})
+	if bucket, ok := me.serviceLimiters[service]; ok {
+		return bucket
+	}
+	return me.sharedLimiter
+}
@@ -0,0 +1,42 @@
+package connectivity
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTokenBucketStopsWithContext(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{RequestsPerSecond: 0.001, Burst: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := bucket.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := bucket.Wait(ctx); err != context.Canceled {
+		t.Errorf("err = %v, want %v once the bucket is empty and the context cancelled", err, context.Canceled)
+	}
+	if bucket.tokens < -0.5 {
+		t.Errorf("bucket holds %.2f tokens, want the cancelled wait's token back", bucket.tokens)
+	}
+}
+
+func TestLimiterPerService(t *testing.T) {
+	client := &WangSuClient{
+		RateLimit: RateLimit{RequestsPerSecond: 10},
+		ServiceRateLimits: map[string]RateLimit{
+			EndpointWaap: {RequestsPerSecond: 5},
+		},
+	}
+	derived := client.WithServiceType("waap-test")
+
+	if client.limiter(EndpointCdn) != client.limiter(EndpointSsl) {
+		t.Error("services without an override do not share the limiter")
+	}
+	if client.limiter(EndpointWaap) == client.limiter(EndpointCdn) {
+		t.Error("the overridden service shares the limiter")
+	}
+	if derived.limiter(EndpointWaap) != client.limiter(EndpointWaap) {
+		t.Error("a derived client has limiters of its own")
+	}
+}
@@ -0,0 +1,88 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 5
+	DefaultRetryMaxWait = 30 * time.Second
+
+	retryMinWait = 1 * time.Second
+)
+
+// retryableStatuses are the HTTP statuses of a call that failed before the API
+// processed it, so that calling again cannot apply it twice:
+//
+//   - 429 Too Many Requests, the client sent too many requests (RFC 6585,
+//     section 4);
+//   - 503 Service Unavailable, the server is temporarily unable to handle the
+//     request because of overload or maintenance (RFC 9110, section 15.6.4).
+//
+// The Wangsu OpenAPI documentation names no error code as safe to retry, so no
+// code is retried on its own. 500, 502 and 504 are not retried either: the call
+// may have been applied, e.g. a rule created, before the gateway failed.
+var retryableStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusServiceUnavailable: true,
+}
+
+// statusError is implemented by errors that know the HTTP status of the failed
+// response, such as common.APIError and wangsu/http.Error.
+type statusError interface {
+	error
+	StatusCode() int
+}
+
+// RetryPolicy controls how throttled and transient API failures are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retrying.
+	MaxRetries int
+	// MaxWait caps a single backoff.
+	MaxWait time.Duration
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxRetries < 0 {
+		p.MaxRetries = 0
+	}
+	if p.MaxWait <= 0 {
+		p.MaxWait = DefaultRetryMaxWait
+	}
+	if p.MaxWait < retryMinWait {
+		p.MaxWait = retryMinWait
+	}
+	return p
+}
+
+// backoff returns an exponentially growing wait with jitter, so that parallel
+// operations throttled at the same moment do not retry in lockstep.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.MaxWait
+	if attempt < 16 && retryMinWait<<uint(attempt) < ceiling {
+		ceiling = retryMinWait << uint(attempt)
+	}
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+// retryable reports whether a failed call may be sent again, and why.
+func retryable(err error) (bool, string) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, ""
+	}
+	// the connection could not be established, the request was never sent
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true, err.Error()
+	}
+	var statusErr statusError
+	if errors.As(err, &statusErr) && retryableStatuses[statusErr.StatusCode()] {
+		return true, http.StatusText(statusErr.StatusCode())
+	}
+	return false, ""
+}
@@ -0,0 +1,35 @@
+package connectivity
+
+// WithServiceType returns a client whose requests carry serviceType instead of
+// the provider's service_type, for resources that override it. It returns the
+// client itself when serviceType is empty or already the provider's one.
+// Derived clients are cached per service type and share the credential,
+// endpoints and rate limiters of the provider's client.
+func (me *WangSuClient) WithServiceType(serviceType string) *WangSuClient {
+	if serviceType == "" || serviceType == me.ServiceType {
+		return me
+	}
+	root := me
+	if me.parent != nil {
+		root = me.parent
+	}
+	if client, ok := root.serviceTypeClients.Load(serviceType); ok {
+		return client.(*WangSuClient)
+	}
+	client, _ := root.serviceTypeClients.LoadOrStore(serviceType, &WangSuClient{
+		Credential:        root.Credential,
+		Domain:            root.Domain,
+		Protocol:          root.Protocol,
+		ServiceType:       serviceType,
+		Endpoints:         root.Endpoints,
+		RetryPolicy:       root.RetryPolicy,
+		HTTPOptions:       root.HTTPOptions,
+		UserAgent:         root.UserAgent,
+		UserAgentSuffix:   root.UserAgentSuffix,
+		RateLimit:         root.RateLimit,
+		ServiceRateLimits: root.ServiceRateLimits,
+		StopContext:       root.StopContext,
+		parent:            root,
+	})
+	return client.(*WangSuClient)
+}
@@ -0,0 +1,24 @@
+package connectivity
+
+// Sites of the Wangsu console. Each site has its own OpenAPI gateway, an account
+// opened on the international console cannot call the mainland one.
+const (
+	SiteCn   = "cn"
+	SiteIntl = "intl"
+)
+
+var Sites = []string{SiteCn, SiteIntl}
+
+var siteDomains = map[string]string{
+	SiteCn:   "open.chinanetcenter.com",
+	SiteIntl: "open.cdnetworks.com",
+}
+
+// SiteDomain returns the default API domain of a site, the mainland one for an
+// empty or unknown site.
+func SiteDomain(site string) string {
+	if domain, ok := siteDomains[site]; ok {
+		return domain
+	}
+	return siteDomains[SiteCn]
+}
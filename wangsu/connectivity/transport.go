@@ -0,0 +1,117 @@
+package connectivity
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// The SDK clients accept no http.Client, so their requests cannot be configured
+// here; Call applies the provider's policies around their calls instead. The
+// clients the provider builds itself, such as the content client, send their
+// requests through a chain of round-trippers that carries the connection
+// settings, the User-Agent and the wire logs of their WangSuClient.
+
+// serviceTransport sends the requests of one service through the chain of a
+// client, so that they are logged as the service's.
+type serviceTransport struct {
+	client  *WangSuClient
+	service string
+}
+
+func (t serviceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport, err := t.client.HTTPTransport()
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(req.WithContext(withService(req.Context(), t.service)))
+}
+
+// httpClient returns an http.Client that sends the requests of service through
+// the client's chain.
+func (me *WangSuClient) httpClient(service string) *http.Client {
+	return &http.Client{Transport: serviceTransport{client: me, service: service}}
+}
+
+// HTTPTransport returns the client's chain, built on first use. The provider
+// calls it when it is configured, to report invalid connection settings at once.
+func (me *WangSuClient) HTTPTransport() (http.RoundTripper, error) {
+	root := me.rootClient()
+	root.transportOnce.Do(func() {
+		root.transport, root.transportErr = root.newTransport()
+	})
+	return root.transport, root.transportErr
+}
+
+func (me *WangSuClient) newTransport() (http.RoundTripper, error) {
+	base, err := me.HTTPOptions.wrap(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	var transport http.RoundTripper = newLoggingTransport(base)
+	if me.UserAgent != "" {
+		transport = &userAgentTransport{
+			next:      transport,
+			userAgent: me.UserAgent,
+			suffix:    strings.TrimSpace(me.UserAgentSuffix),
+		}
+	}
+	return &stopTransport{next: transport, stop: me.stopContext()}, nil
+}
+
+// rootClient returns the provider's client, the one derived clients were
+// derived from.
+func (me *WangSuClient) rootClient() *WangSuClient {
+	if me.parent != nil {
+		return me.parent
+	}
+	return me
+}
+
+func (me *WangSuClient) stopContext() context.Context {
+	if me.StopContext == nil {
+		return context.Background()
+	}
+	return me.StopContext
+}
+
+// stopTransport aborts in-flight requests once Terraform asks the provider to
+// stop, including requests of operations whose context Terraform does not
+// cancel.
+type stopTransport struct {
+	next http.RoundTripper
+	stop context.Context
+}
+
+func (t *stopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.stop.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	stopAfter := context.AfterFunc(t.stop, cancel)
+	release := func() {
+		stopAfter()
+		cancel()
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseOnClose keeps the request context alive until the body has been read.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,113 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wangsu-api/wangsu-sdk-go/wangsu/common"
+)
+
+// newTestServer answers like the OpenAPI, throttling the first throttled
+// requests, and records the User-Agent of the last request.
+func newTestServer(t *testing.T, throttled int32, userAgent *atomic.Value) (*httptest.Server, *atomic.Int32) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.UserAgent())
+		w.Header().Set("Content-Type", "application/json")
+		if attempts.Add(1) <= throttled {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"code":"Throttling","message":"too many requests"}`)
+			return
+		}
+		fmt.Fprint(w, `{"code":"0","message":"success"}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func newContentTestClient(endpoint string) *WangSuClient {
+	return &WangSuClient{
+		Credential:      common.NewCredential("ak-test", "sk-test"),
+		Endpoints:       map[string]string{EndpointCdn: endpoint},
+		RetryPolicy:     RetryPolicy{MaxRetries: 2, MaxWait: retryMinWait},
+		UserAgent:       UserAgent("1.2.0", "1.9.5"),
+		UserAgentSuffix: "ci",
+	}
+}
+
+func TestUseContentClientSendsThroughTheChain(t *testing.T) {
+	var userAgent atomic.Value
+	server, attempts := newTestServer(t, 1, &userAgent)
+	client := newContentTestClient(server.URL)
+	contentClient, err := client.UseContentClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithResourceType(context.Background(), "wangsu_cdn_purge")
+	err = client.Call(ctx, EndpointCdn, "purge", func() (string, error) {
+		return contentClient.Do(ctx, http.MethodPost, "/ccm/purge/ItemIdQuery", nil, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+	if got, want := userAgent.Load(), "terraform-provider-wangsu/1.2.0 terraform/1.9.5 (wangsu_cdn_purge) ci"; got != want {
+		t.Errorf("User-Agent = %q, want %q", got, want)
+	}
+}
+
+func TestStopTransportAbortsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	stop, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newContentTestClient(server.URL)
+	client.StopContext = stop
+	contentClient, err := client.UseContentClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = contentClient.Do(context.Background(), http.MethodPost, "/ccm/purge/ItemIdQuery", nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the request took %s after the provider was stopped", elapsed)
+	}
+}
+
+func TestHTTPTransportIsShared(t *testing.T) {
+	client := &WangSuClient{}
+	transport, err := client.HTTPTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived, err := client.WithServiceType("waap-test").HTTPTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transport != derived {
+		t.Error("a derived client has a transport of its own")
+	}
+}
+
+func TestHTTPTransportReportsInvalidOptions(t *testing.T) {
+	client := &WangSuClient{HTTPOptions: HTTPOptions{CABundleFile: "/nonexistent/ca.pem"}}
+	if _, err := client.HTTPTransport(); err == nil {
+		t.Error("no error for a missing CA bundle")
+	}
+}
@@ -0,0 +1,45 @@
+package connectivity
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// UserAgent returns the User-Agent of the provider's requests, e.g.
+// "terraform-provider-wangsu/1.2.0 terraform/1.9.5".
+func UserAgent(providerVersion, terraformVersion string) string {
+	if providerVersion == "" {
+		providerVersion = "dev"
+	}
+	userAgent := fmt.Sprintf("terraform-provider-wangsu/%s", providerVersion)
+	if terraformVersion != "" {
+		userAgent += fmt.Sprintf(" terraform/%s", terraformVersion)
+	}
+	return userAgent
+}
+
+// userAgentTransport puts the provider's User-Agent in front of the one the
+// client sends, if any, so that the API gateway can tell Terraform traffic apart. The resource
+// type of the request's operation follows in parentheses, then the suffix, e.g.
+// "terraform-provider-wangsu/1.2.0 terraform/1.9.5 (wangsu_cdn_domain) ci".
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+	suffix    string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	userAgent := t.userAgent
+	if resourceType := resourceTypeFromContext(req.Context()); resourceType != "" {
+		userAgent += " (" + resourceType + ")"
+	}
+	if t.suffix != "" {
+		userAgent += " " + t.suffix
+	}
+	if clientUserAgent := req.Header.Get("User-Agent"); clientUserAgent != "" {
+		userAgent += " " + clientUserAgent
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent)
+	return t.next.RoundTrip(req)
+}
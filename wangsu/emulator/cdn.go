@@ -0,0 +1,63 @@
+package emulator
+
+import (
+	"net/http"
+)
+
+// deployment is a deployment started by a CDN domain write, identified by the
+// request id of the write.
+type deployment struct {
+	polls  int
+	reason string
+}
+
+// FailNextDeployment makes the next deployment fail with reason.
+func (s *Server) FailNextDeployment(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, reason)
+}
+
+func (s *Server) startDeployment(call *Call) {
+	d := &deployment{polls: s.DeployPolls}
+	if len(s.failures) > 0 {
+		d.reason, s.failures = s.failures[0], s.failures[1:]
+	}
+	s.deployments[call.RequestId] = d
+}
+
+func registerCdn(s *Server) {
+	resourceApi{
+		kind:    "cdn_domain",
+		prefix:  "/cdn/domain",
+		idField: "domainName",
+		created: func(string, map[string]interface{}) interface{} { return nil },
+		onWrite: func(call *Call, _ string) { s.startDeployment(call) },
+	}.register(s)
+
+	// Deployment status of the request that created, updated or deleted a domain.
+	s.Handle("GET", "/cdn/deploy-status/{requestId}", func(call *Call) (interface{}, error) {
+		requestId := call.Param("requestId")
+		d, ok := s.deployments[requestId]
+		if !ok {
+			return nil, &Error{HttpStatus: http.StatusNotFound, Code: "ResourceNotFound", Message: "deployment " + requestId + " does not exist"}
+		}
+		status := "DEPLOYING"
+		switch {
+		case d.polls > 0:
+			d.polls--
+		case d.reason != "":
+			status = "FAIL"
+		default:
+			status = "SUCCESS"
+		}
+		data := map[string]interface{}{
+			"requestId":    requestId,
+			"deployResult": status,
+		}
+		if status == "FAIL" {
+			data["message"] = d.reason
+		}
+		return data, nil
+	})
+}
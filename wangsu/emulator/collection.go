@@ -0,0 +1,267 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Collection stores the objects of one kind as JSON documents, keyed by id.
+type Collection struct {
+	Kind string
+
+	mu   sync.Mutex
+	docs map[string]map[string]interface{}
+}
+
+func newCollection(kind string) *Collection {
+	return &Collection{Kind: kind, docs: make(map[string]map[string]interface{})}
+}
+
+// Put stores doc under id, replacing any previous document.
+func (c *Collection) Put(id string, doc map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docs[id] = copyDoc(doc)
+}
+
+// Get returns a copy of the document stored under id.
+func (c *Collection) Get(id string) (map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc, ok := c.docs[id]
+	if !ok {
+		return nil, false
+	}
+	return copyDoc(doc), true
+}
+
+// Merge sets the fields of patch on the document stored under id. Fields that
+// are null in patch are left unchanged, as the update APIs do.
+func (c *Collection) Merge(id string, patch map[string]interface{}) (map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc, ok := c.docs[id]
+	if !ok {
+		return nil, false
+	}
+	for key, value := range patch {
+		if value != nil {
+			doc[key] = value
+		}
+	}
+	return copyDoc(doc), true
+}
+
+// Delete removes the document stored under id and reports whether it existed.
+func (c *Collection) Delete(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.docs[id]
+	delete(c.docs, id)
+	return ok
+}
+
+// List returns the documents matching filter, ordered by id. A scalar filter
+// field must equal the document field of the same name; a list field, such as
+// domainList, must contain the document field named without the List suffix.
+// Empty filter fields are ignored.
+func (c *Collection) List(filter map[string]interface{}) []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]string, 0, len(c.docs))
+	for id := range c.docs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		left, leftErr := strconv.ParseInt(ids[i], 10, 64)
+		right, rightErr := strconv.ParseInt(ids[j], 10, 64)
+		if leftErr == nil && rightErr == nil {
+			return left < right
+		}
+		return ids[i] < ids[j]
+	})
+
+	docs := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		if matches(c.docs[id], filter) {
+			docs = append(docs, copyDoc(c.docs[id]))
+		}
+	}
+	return docs
+}
+
+// Len returns the number of stored documents.
+func (c *Collection) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.docs)
+}
+
+func matches(doc, filter map[string]interface{}) bool {
+	for key, want := range filter {
+		switch want := want.(type) {
+		case nil:
+		case []interface{}:
+			if len(want) == 0 {
+				continue
+			}
+			field := strings.TrimSuffix(key, "List")
+			if _, ok := doc[field]; !ok {
+				continue
+			}
+			found := false
+			for _, value := range want {
+				if fmt.Sprint(value) == fmt.Sprint(doc[field]) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		case map[string]interface{}:
+		default:
+			if fmt.Sprint(want) == "" {
+				continue
+			}
+			if value, ok := doc[key]; ok && fmt.Sprint(value) != fmt.Sprint(want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func copyDoc(doc map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(doc))
+	for key, value := range doc {
+		copied[key] = value
+	}
+	return copied
+}
+
+// resourceApi describes a REST collection of the API and how its create call
+// answers.
+type resourceApi struct {
+	kind   string
+	prefix string
+	// idField is the document field holding the id. When the create request does
+	// not set it, a sequential id is generated, numeric when numericId is set.
+	idField   string
+	numericId bool
+	// created builds the data of the create response, the id by default.
+	created func(id string, doc map[string]interface{}) interface{}
+	// updated builds the data of the update response, none by default.
+	updated func(id string, doc map[string]interface{}) interface{}
+	// onWrite is called after every create, update and delete, e.g. to start a
+	// deployment.
+	onWrite func(call *Call, id string)
+}
+
+// register adds the routes of a collection:
+//
+//	POST   prefix         create
+//	GET    prefix         list, filtered by the query string
+//	POST   prefix/list    list, filtered by the body
+//	GET    prefix/{id}    read
+//	PUT    prefix/{id}    update
+//	PUT    prefix         update, the id in the body
+//	DELETE prefix/{id}    delete
+//	POST   prefix/delete  delete the ids of the idList body field
+func (api resourceApi) register(s *Server) {
+	collection := s.collection(api.kind)
+	idParam := "{" + api.idField + "}"
+
+	s.Handle("POST", api.prefix, func(call *Call) (interface{}, error) {
+		doc := copyDoc(call.Body)
+		id := call.Param(api.idField)
+		if id == "" {
+			number := s.nextId()
+			id = strconv.FormatInt(number, 10)
+			if api.numericId {
+				doc[api.idField] = number
+			} else {
+				doc[api.idField] = id
+			}
+		}
+		if _, exists := collection.Get(id); exists {
+			return nil, &Error{HttpStatus: 409, Code: "ResourceAlreadyExists", Message: fmt.Sprintf("%s %s already exists", api.kind, id)}
+		}
+		collection.Put(id, doc)
+		api.written(call, id)
+		if api.created != nil {
+			return api.created(id, doc), nil
+		}
+		return id, nil
+	})
+	list := func(filter map[string]interface{}) (interface{}, error) {
+		return collection.List(filter), nil
+	}
+	s.Handle("GET", api.prefix, func(call *Call) (interface{}, error) {
+		filter := make(map[string]interface{}, len(call.Query))
+		for key := range call.Query {
+			filter[key] = call.Query.Get(key)
+		}
+		return list(filter)
+	})
+	s.Handle("POST", api.prefix+"/list", func(call *Call) (interface{}, error) {
+		return list(call.Body)
+	})
+	s.Handle("GET", api.prefix+"/"+idParam, func(call *Call) (interface{}, error) {
+		id := call.Param(api.idField)
+		doc, ok := collection.Get(id)
+		if !ok {
+			return nil, NotFound(api.kind, id)
+		}
+		return doc, nil
+	})
+	update := func(call *Call) (interface{}, error) {
+		id := call.Param(api.idField)
+		if id == "" {
+			return nil, InvalidParameter(api.idField, "is required")
+		}
+		patch := copyDoc(call.Body)
+		delete(patch, api.idField)
+		doc, ok := collection.Merge(id, patch)
+		if !ok {
+			return nil, NotFound(api.kind, id)
+		}
+		api.written(call, id)
+		if api.updated != nil {
+			return api.updated(id, doc), nil
+		}
+		return nil, nil
+	}
+	s.Handle("PUT", api.prefix+"/"+idParam, update)
+	s.Handle("PUT", api.prefix, update)
+	s.Handle("DELETE", api.prefix+"/"+idParam, func(call *Call) (interface{}, error) {
+		id := call.Param(api.idField)
+		if !collection.Delete(id) {
+			return nil, NotFound(api.kind, id)
+		}
+		api.written(call, id)
+		return nil, nil
+	})
+	s.Handle("POST", api.prefix+"/delete", func(call *Call) (interface{}, error) {
+		ids := stringList(call.Body["idList"])
+		if len(ids) == 0 {
+			return nil, InvalidParameter("idList", "at least one id is required")
+		}
+		for _, id := range ids {
+			if !collection.Delete(id) {
+				return nil, NotFound(api.kind, id)
+			}
+			api.written(call, id)
+		}
+		return nil, nil
+	})
+}
+
+func (api resourceApi) written(call *Call, id string) {
+	if api.onWrite != nil {
+		api.onWrite(call, id)
+	}
+}
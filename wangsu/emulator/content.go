@@ -0,0 +1,83 @@
+package emulator
+
+import (
+	"fmt"
+)
+
+// contentTask is a purge or prefetch task. Its URLs are processing for the
+// first polls queries, then they succeed, except those passed to FailUrls.
+type contentTask struct {
+	urls  []string
+	polls int
+}
+
+// FailUrls makes the purge and prefetch of the given URLs and directories fail.
+func (s *Server) FailUrls(urls ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, url := range urls {
+		s.failedUrls[url] = true
+	}
+}
+
+// registerContentApi adds the receiver and query routes of a kind of content
+// task. They answer with the envelope of the content management APIs, code 1 on
+// success, instead of the one of the OpenAPI.
+func registerContentApi(s *Server, receiverPath, queryPath string, urls func(call *Call) []string) {
+	s.Handle("POST", receiverPath, func(call *Call) (interface{}, error) {
+		task := &contentTask{urls: urls(call), polls: s.DeployPolls}
+		if len(task.urls) == 0 {
+			return nil, InvalidParameter("urls", "at least one URL is required")
+		}
+		itemId := fmt.Sprintf("task-%d", s.nextId())
+		s.tasks[itemId] = task
+		return Envelope{"Code": 1, "Message": "handle success", "itemId": itemId}, nil
+	})
+	s.Handle("POST", queryPath, func(call *Call) (interface{}, error) {
+		itemId := call.Param("itemId")
+		task, ok := s.tasks[itemId]
+		if !ok {
+			return nil, NotFound("task", itemId)
+		}
+		status := "PROCESSING"
+		if task.polls > 0 {
+			task.polls--
+		} else {
+			status = "SUCCESS"
+		}
+		results := make([]interface{}, 0, len(task.urls))
+		for _, url := range task.urls {
+			urlStatus := status
+			if status == "SUCCESS" && s.failedUrls[url] {
+				urlStatus = "FAILURE"
+			}
+			results = append(results, map[string]interface{}{"itemId": itemId, "url": url, "status": urlStatus})
+		}
+		return Envelope{"code": 1, "message": "success", "resultList": results}, nil
+	})
+}
+
+func registerContent(s *Server) {
+	registerContentApi(s, "/ccm/purge/ItemIdReceiver", "/ccm/purge/ItemIdQuery", func(call *Call) []string {
+		return append(stringList(call.Body["urls"]), stringList(call.Body["dirs"])...)
+	})
+	registerContentApi(s, "/ccm/fetch/ItemIdReceiver", "/ccm/fetch/ItemIdQuery", func(call *Call) []string {
+		var urls []string
+		list, _ := call.Body["list"].([]interface{})
+		for _, item := range list {
+			if item, ok := item.(map[string]interface{}); ok {
+				urls = append(urls, fmt.Sprint(item["url"]))
+			}
+		}
+		return urls
+	})
+}
+
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	strings := make([]string, 0, len(list))
+	for _, item := range list {
+		strings = append(strings, fmt.Sprint(item))
+	}
+	return strings
+}
@@ -0,0 +1,366 @@
+// Package emulator is an in-memory fake of the Wangsu OpenAPI for running the
+// provider's acceptance tests offline. It serves the endpoints of the CDN domain,
+// SSL certificate, WAAP, IAM, monitor and content purge and prefetch APIs that
+// connectivity.WangSuClient calls, keeping the created objects in memory so that
+// a resource.Test can create, read, update, import and destroy resources without
+// a Wangsu account. Its routes are checked against the requests of the SDK by
+// CheckRoutes, which every test calls:
+//
+//	server := emulator.New()
+//	defer server.Close()
+//	resource.Test(t, resource.TestCase{
+//		ProviderFactories: server.ProviderFactories(),
+//		Steps: []resource.TestStep{{
+//			Config: server.ProviderConfig() + testAccCdnDomainConfig,
+//		}},
+//	})
+//	if err := server.CheckRoutes(); err != nil {
+//		t.Error(err)
+//	}
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
+)
+
+// Call is one API request, decoded for a HandlerFunc.
+type Call struct {
+	Method string
+	Path   string
+	// Params holds the values of the {name} segments of the route pattern.
+	Params    map[string]string
+	Query     url.Values
+	Body      map[string]interface{}
+	RequestId string
+	header    http.Header
+}
+
+// Param returns a request parameter looked up in the path, the query string and
+// the body, in this order.
+func (c *Call) Param(name string) string {
+	if value, ok := c.Params[name]; ok {
+		return value
+	}
+	if value := c.Query.Get(name); value != "" {
+		return value
+	}
+	if value, ok := c.Body[name]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// SetHeader adds a header to the response.
+func (c *Call) SetHeader(name, value string) {
+	c.header.Set(name, value)
+}
+
+// HandlerFunc serves one route. The returned value is sent as the data of the
+// response, an Envelope as the whole response; an *Error is sent as an API
+// error, other errors as internal errors.
+type HandlerFunc func(call *Call) (interface{}, error)
+
+// Envelope is the whole body of a response, for the APIs that do not wrap their
+// data in the code, message and data envelope of the OpenAPI.
+type Envelope map[string]interface{}
+
+// Error is an error response of the API.
+type Error struct {
+	HttpStatus int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.HttpStatus, e.Code, e.Message)
+}
+
+// NotFound returns the error of the API for an unknown object.
+func NotFound(kind, id string) *Error {
+	return &Error{HttpStatus: http.StatusNotFound, Code: "ResourceNotFound", Message: fmt.Sprintf("%s %s does not exist", kind, id)}
+}
+
+// InvalidParameter returns the error of the API for an invalid request parameter.
+func InvalidParameter(name, message string) *Error {
+	return &Error{HttpStatus: http.StatusBadRequest, Code: "InvalidParameter", Message: fmt.Sprintf("[%s] %s", name, message)}
+}
+
+// RecordedRequest is a request received by the emulator.
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+type route struct {
+	method   string
+	segments []string
+	handler  HandlerFunc
+}
+
+// Server is a running emulator. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// DeployPolls is the number of deployment status queries that report a
+	// deployment as in progress before it succeeds.
+	DeployPolls int
+
+	mu          sync.Mutex
+	routes      []route
+	collections map[string]*Collection
+	deployments map[string]*deployment
+	failures    []string
+	tasks       map[string]*contentTask
+	failedUrls  map[string]bool
+	requests    []RecordedRequest
+	unrouted    []RecordedRequest
+	sequence    int64
+}
+
+// New starts an emulator serving every supported API.
+func New() *Server {
+	s := &Server{
+		DeployPolls: 1,
+		collections: make(map[string]*Collection),
+		deployments: make(map[string]*deployment),
+		tasks:       make(map[string]*contentTask),
+		failedUrls:  make(map[string]bool),
+	}
+	registerCdn(s)
+	registerSsl(s)
+	registerWaap(s)
+	registerIam(s)
+	registerMonitor(s)
+	registerContent(s)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Handle adds a route, or replaces the route with the same method and pattern.
+// Segments of pattern written as {name} match any value, available through
+// Call.Param.
+func (s *Server) Handle(method, pattern string, handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	segments := splitPath(pattern)
+	for i, r := range s.routes {
+		if r.method == method && strings.Join(r.segments, "/") == strings.Join(segments, "/") {
+			s.routes[i].handler = handler
+			return
+		}
+	}
+	s.routes = append(s.routes, route{method: method, segments: segments, handler: handler})
+}
+
+// Collection returns the objects of a kind, e.g. "cdn_domain", so that tests can
+// seed or inspect them.
+func (s *Server) Collection(kind string) *Collection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.collection(kind)
+}
+
+func (s *Server) collection(kind string) *Collection {
+	c, ok := s.collections[kind]
+	if !ok {
+		c = newCollection(kind)
+		s.collections[kind] = c
+	}
+	return c
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// CheckRoutes returns an error listing the requests that matched no route.
+// The routes are not generated from the SDK, so every acceptance test calls it
+// after resource.Test to check them against the requests the SDK really sends,
+// failing paths included. An unrouted request means that a route is wrong, that
+// an SDK update moved an API, or that a resource calls an API the emulator does
+// not serve yet; the error lists the method and path to route.
+func (s *Server) CheckRoutes() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.unrouted) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(s.unrouted))
+	for _, r := range s.unrouted {
+		lines = append(lines, r.Method+" "+r.Path)
+	}
+	return fmt.Errorf("the emulator has no route for %d requests:\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+// Endpoints returns the endpoint of every service, all pointing at the emulator.
+func (s *Server) Endpoints() map[string]string {
+	endpoints := make(map[string]string, len(connectivity.EndpointServices))
+	for _, service := range connectivity.EndpointServices {
+		endpoints[service] = s.URL
+	}
+	return endpoints
+}
+
+// ProviderConfig returns a provider block that sends every request to the
+// emulator.
+func (s *Server) ProviderConfig() string {
+	var builder strings.Builder
+	builder.WriteString("provider \"wangsu\" {\n")
+	builder.WriteString("  secret_id                   = \"emulator\"\n")
+	builder.WriteString("  secret_key                  = \"emulator\"\n")
+	builder.WriteString("  skip_credentials_validation = true\n")
+	builder.WriteString("  endpoints {\n")
+	services := append([]string(nil), connectivity.EndpointServices...)
+	sort.Strings(services)
+	for _, service := range services {
+		fmt.Fprintf(&builder, "    %s = %q\n", service, s.URL)
+	}
+	builder.WriteString("  }\n}\n")
+	return builder.String()
+}
+
+// ProviderFactories returns the provider factories of a resource.TestCase.
+func (s *Server) ProviderFactories() map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"wangsu": func() (*schema.Provider, error) {
+			return wangsu.Provider(), nil
+		},
+	}
+}
+
+func (s *Server) nextId() int64 {
+	s.sequence++
+	return s.sequence
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	call := &Call{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		header: w.Header(),
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, call, &Error{HttpStatus: http.StatusBadRequest, Code: "InvalidRequest", Message: err.Error()})
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &call.Body); err != nil {
+			writeError(w, call, &Error{HttpStatus: http.StatusBadRequest, Code: "InvalidRequest", Message: "request body is not a JSON object"})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	call.RequestId = fmt.Sprintf("emulator-%d", s.nextId())
+	recorded := RecordedRequest{Method: call.Method, Path: call.Path, Body: call.Body}
+	s.requests = append(s.requests, recorded)
+	log.Printf("[DEBUG] emulator: %s %s", call.Method, call.Path)
+
+	handler := s.match(call)
+	if handler == nil {
+		s.unrouted = append(s.unrouted, recorded)
+		writeError(w, call, &Error{HttpStatus: http.StatusNotFound, Code: "ApiNotFound", Message: fmt.Sprintf("no route for %s %s", call.Method, call.Path)})
+		return
+	}
+	data, err := handler(call)
+	if err != nil {
+		writeError(w, call, err)
+		return
+	}
+	if envelope, ok := data.(Envelope); ok {
+		writeJSON(w, call, http.StatusOK, envelope)
+		return
+	}
+	writeJSON(w, call, http.StatusOK, map[string]interface{}{
+		"code":    "0",
+		"message": "success",
+		"data":    data,
+	})
+}
+
+// match returns the handler of the route matching call, preferring the route
+// with the most literal segments.
+func (s *Server) match(call *Call) HandlerFunc {
+	segments := splitPath(call.Path)
+	var best *route
+	bestLiterals := -1
+	var bestParams map[string]string
+	for i := range s.routes {
+		r := &s.routes[i]
+		if r.method != call.Method || len(r.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		literals := 0
+		matched := true
+		for j, segment := range r.segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				value, _ := url.PathUnescape(segments[j])
+				params[segment[1:len(segment)-1]] = value
+				continue
+			}
+			if segment != segments[j] {
+				matched = false
+				break
+			}
+			literals++
+		}
+		if matched && literals > bestLiterals {
+			best, bestLiterals, bestParams = r, literals, params
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	call.Params = bestParams
+	return best.handler
+}
+
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+func writeError(w http.ResponseWriter, call *Call, err error) {
+	apiErr, ok := err.(*Error)
+	if !ok {
+		apiErr = &Error{HttpStatus: http.StatusInternalServerError, Code: "InternalError", Message: err.Error()}
+	}
+	writeJSON(w, call, apiErr.HttpStatus, map[string]interface{}{
+		"code":      apiErr.Code,
+		"message":   apiErr.Message,
+		"requestId": call.RequestId,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, call *Call, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-cnc-request-id", call.RequestId)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		log.Printf("[WARN] emulator: failed to write response: %s", err)
+	}
+}
@@ -0,0 +1,64 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+)
+
+func registerIam(s *Server) {
+	resourceApi{
+		kind:      "iam_policy",
+		prefix:    "/api/iam/policy",
+		idField:   "policyId",
+		numericId: true,
+		created: func(_ string, doc map[string]interface{}) interface{} {
+			return map[string]interface{}{"policyId": doc["policyId"]}
+		},
+	}.register(s)
+	resourceApi{
+		kind:    "iam_user",
+		prefix:  "/api/iam/user",
+		idField: "loginName",
+		created: func(id string, _ map[string]interface{}) interface{} {
+			return map[string]interface{}{"loginName": id}
+		},
+	}.register(s)
+
+	// Policies attached to an account, stored as {"policyName": [...]} by login name.
+	attachments := s.collection("iam_policy_attachment")
+	s.Handle("POST", "/api/iam/policy/attachment", func(call *Call) (interface{}, error) {
+		loginName := call.Param("loginName")
+		if loginName == "" {
+			return nil, InvalidParameter("loginName", "is required")
+		}
+		names := map[string]bool{}
+		if doc, ok := attachments.Get(loginName); ok {
+			for _, name := range doc["policyName"].([]interface{}) {
+				names[fmt.Sprint(name)] = true
+			}
+		}
+		policyNames, _ := call.Body["policyName"].([]interface{})
+		revoke := call.Param("type") == "1"
+		for _, name := range policyNames {
+			names[fmt.Sprint(name)] = !revoke
+		}
+		attached := make([]interface{}, 0, len(names))
+		for name, ok := range names {
+			if ok {
+				attached = append(attached, name)
+			}
+		}
+		sort.Slice(attached, func(i, j int) bool { return attached[i].(string) < attached[j].(string) })
+		attachments.Put(loginName, map[string]interface{}{"policyName": attached})
+		return nil, nil
+	})
+	s.Handle("GET", "/api/iam/policy/attachment/{loginName}", func(call *Call) (interface{}, error) {
+		policies := []interface{}{}
+		if doc, ok := attachments.Get(call.Param("loginName")); ok {
+			for _, name := range doc["policyName"].([]interface{}) {
+				policies = append(policies, map[string]interface{}{"policyName": name})
+			}
+		}
+		return policies, nil
+	})
+}
@@ -0,0 +1,15 @@
+package emulator
+
+func registerMonitor(s *Server) {
+	resourceApi{
+		kind:    "monitor_realtime_rule",
+		prefix:  "/api/monitor/realtime-rule",
+		idField: "ruleId",
+		created: func(id string, _ map[string]interface{}) interface{} {
+			return map[string]interface{}{"ruleId": id}
+		},
+		updated: func(id string, _ map[string]interface{}) interface{} {
+			return map[string]interface{}{"ruleId": id}
+		},
+	}.register(s)
+}
@@ -0,0 +1,13 @@
+package emulator
+
+func registerSsl(s *Server) {
+	resourceApi{
+		kind:      "ssl_certificate",
+		prefix:    "/api/certificate",
+		idField:   "certificateId",
+		numericId: true,
+		created: func(_ string, doc map[string]interface{}) interface{} {
+			return map[string]interface{}{"certificateId": doc["certificateId"]}
+		},
+	}.register(s)
+}
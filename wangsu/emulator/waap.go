@@ -0,0 +1,24 @@
+package emulator
+
+func registerWaap(s *Server) {
+	resourceApi{
+		kind:    "waap_domain",
+		prefix:  "/api/v1/waap/domain",
+		idField: "domain",
+	}.register(s)
+	resourceApi{
+		kind:    "waap_whitelist",
+		prefix:  "/api/v1/waap/whitelist",
+		idField: "id",
+	}.register(s)
+	resourceApi{
+		kind:    "waap_ratelimit",
+		prefix:  "/api/v1/waap/ratelimit",
+		idField: "id",
+	}.register(s)
+	resourceApi{
+		kind:    "waap_customizerule",
+		prefix:  "/api/v1/waap/customizerule",
+		idField: "id",
+	}.register(s)
+}
@@ -1 +1,99 @@
+// Package http sends signed requests to the Wangsu OpenAPI for the APIs that
+// wangsu-sdk-go has no client for, such as content purge and prefetch, and for
+// the fields its types do not carry, such as the visit control rules of a CDN
+// domain. The
+// provider gives its clients an http.Client that carries its connection
+// settings, User-Agent and logs, and makes their calls through
+// connectivity.WangSuClient.Call like the SDK's.
 package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client sends requests to one API domain, signed with an access key.
+type Client struct {
+	SecretId  string
+	SecretKey string
+	// BaseURL is the scheme and host of the API, such as https://open.chinanetcenter.com.
+	BaseURL    string
+	HTTPClient *http.Client
+	// Now returns the signing time, time.Now when nil.
+	Now func() time.Time
+}
+
+// Error is a response with an HTTP status of 400 or above. Its text holds the
+// status and the body, which common.NewAPIError breaks down.
+type Error struct {
+	HttpStatus int
+	RequestId  string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("status %d: %s", e.HttpStatus, http.StatusText(e.HttpStatus))
+	}
+	return fmt.Sprintf("status %d: %s", e.HttpStatus, e.Body)
+}
+
+// StatusCode returns the HTTP status of the response.
+func (e *Error) StatusCode() int {
+	return e.HttpStatus
+}
+
+// Do sends request, encoded as JSON, to path and decodes the JSON response into
+// response, unless it is nil. It returns the request id of the response.
+func (c *Client) Do(ctx context.Context, method, path string, request, response interface{}) (string, error) {
+	var body []byte
+	if request != nil {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return "", err
+		}
+	}
+	u, err := url.Parse(strings.TrimSuffix(c.BaseURL, "/") + path)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	c.sign(req, body)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	requestId := resp.Header.Get("x-cnc-request-id")
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return requestId, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return requestId, &Error{HttpStatus: resp.StatusCode, RequestId: requestId, Body: strings.TrimSpace(string(respBody))}
+	}
+	if response == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return requestId, nil
+	}
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return requestId, fmt.Errorf("failed to decode the response of %s %s: %w", method, path, err)
+	}
+	return requestId, nil
+}
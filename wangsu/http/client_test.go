@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient(server *httptest.Server) *Client {
+	return &Client{
+		SecretId:   "ak-test",
+		SecretKey:  "sk-test",
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+		Now:        func() time.Time { return time.Unix(1700000000, 0) },
+	}
+}
+
+func TestClientDoSignsRequests(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, gotBody = r, string(body)
+		w.Header().Set("x-cnc-request-id", "r-1")
+		fmt.Fprint(w, `{"Code":1,"Message":"handle success","itemId":"task-1"}`)
+	}))
+	defer server.Close()
+
+	var response struct {
+		Code   int    `json:"code"`
+		ItemId string `json:"itemId"`
+	}
+	requestId, err := newTestClient(server).Do(context.Background(), http.MethodPost, "/ccm/purge/ItemIdReceiver", map[string][]string{"urls": {"http://www.example.com/a.html"}}, &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requestId != "r-1" || response.Code != 1 || response.ItemId != "task-1" {
+		t.Errorf("got request id %q and response %+v", requestId, response)
+	}
+	if want := `{"urls":["http://www.example.com/a.html"]}`; gotBody != want {
+		t.Errorf("body = %s, want %s", gotBody, want)
+	}
+	if got.Header.Get("x-cnc-accessKey") != "ak-test" || got.Header.Get("x-cnc-timestamp") != "1700000000" || got.Header.Get("x-cnc-auth-method") != "AKSK" {
+		t.Errorf("authentication headers = %v", got.Header)
+	}
+
+	bodyHash := sha256.Sum256([]byte(gotBody))
+	canonicalRequest := "POST\n/ccm/purge/ItemIdReceiver\n\ncontent-type:application/json\nhost:" + got.Host + "\n\ncontent-type;host\n" + hex.EncodeToString(bodyHash[:])
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	mac := hmac.New(sha256.New, []byte("sk-test"))
+	mac.Write([]byte("CNC-HMAC-SHA256\n1700000000\n" + hex.EncodeToString(requestHash[:])))
+	want := "CNC-HMAC-SHA256 Credential=ak-test, SignedHeaders=content-type;host, Signature=" + hex.EncodeToString(mac.Sum(nil))
+	if got := got.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestClientDoReturnsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-cnc-request-id", "r-2")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"code":"AccessDenied","message":"no permission"}`)
+	}))
+	defer server.Close()
+
+	requestId, err := newTestClient(server).Do(context.Background(), http.MethodPost, "/ccm/purge/ItemIdQuery", nil, nil)
+	var httpErr *Error
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("err = %v, want an *Error", err)
+	}
+	if requestId != "r-2" || httpErr.HttpStatus != http.StatusForbidden || httpErr.RequestId != "r-2" {
+		t.Errorf("got request id %q and error %+v", requestId, httpErr)
+	}
+	if !strings.Contains(err.Error(), `"code":"AccessDenied"`) {
+		t.Errorf("err = %q, want the response body", err)
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signAlgorithm is the access key signature of the OpenAPI gateway, the one the
+// SDK clients sign with.
+const signAlgorithm = "CNC-HMAC-SHA256"
+
+// signedHeaders are the headers covered by the signature, lower case and sorted.
+var signedHeaders = []string{"content-type", "host"}
+
+// sign adds the authentication headers to req, whose body is body:
+//
+//	CanonicalRequest = Method \n Path \n Query \n CanonicalHeaders \n SignedHeaders \n hex(sha256(Body))
+//	StringToSign     = CNC-HMAC-SHA256 \n Timestamp \n hex(sha256(CanonicalRequest))
+//	Signature        = hex(hmac-sha256(SecretKey, StringToSign))
+func (c *Client) sign(req *http.Request, body []byte) {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.ToLower(strings.TrimSpace(value)) + "\n")
+	}
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		sha256Hex(body),
+	}, "\n")
+	stringToSign := strings.Join([]string{signAlgorithm, timestamp, sha256Hex([]byte(canonicalRequest))}, "\n")
+	mac := hmac.New(sha256.New, []byte(c.SecretKey))
+	mac.Write([]byte(stringToSign))
+
+	req.Header.Set("x-cnc-accessKey", c.SecretId)
+	req.Header.Set("x-cnc-timestamp", timestamp)
+	req.Header.Set("x-cnc-auth-method", "AKSK")
+	req.Header.Set("Authorization", signAlgorithm+" Credential="+c.SecretId+", SignedHeaders="+strings.Join(signedHeaders, ";")+", Signature="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
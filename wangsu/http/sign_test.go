@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignVector checks the signature against a value computed outside of this
+// package, so that a change of the canonical request breaks the test even when
+// it is made on both sides.
+func TestSignVector(t *testing.T) {
+	body := []byte(`{"urls":["http://www.example.com/a.html"]}`)
+	req, err := http.NewRequest(http.MethodPost, "https://open.chinanetcenter.com/ccm/purge/ItemIdReceiver", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &Client{
+		SecretId:  "ak-test",
+		SecretKey: "sk-test",
+		Now:       func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	client.sign(req, body)
+
+	want := "CNC-HMAC-SHA256 Credential=ak-test, SignedHeaders=content-type;host, Signature=4a8a81f489fca59125fea48114f4e090f8a687e12daba9f9fe69d1da4cc4f14e"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+	if got := req.Header.Get("x-cnc-timestamp"); got != "1700000000" {
+		t.Errorf("x-cnc-timestamp = %q, want 1700000000", got)
+	}
+}
+
+func TestSignCanonicalizesTheQuery(t *testing.T) {
+	sign := func(rawURL string) string {
+		req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &Client{SecretId: "ak-test", SecretKey: "sk-test", Now: func() time.Time { return time.Unix(1700000000, 0) }}
+		client.sign(req, nil)
+		return req.Header.Get("Authorization")
+	}
+	a := sign("https://open.chinanetcenter.com/api/domain?b=2&a=1")
+	b := sign("https://open.chinanetcenter.com/api/domain?a=1&b=2")
+	if a != b || !strings.HasPrefix(a, signAlgorithm+" ") {
+		t.Errorf("signatures differ with the order of the query: %q and %q", a, b)
+	}
+}
@@ -2,11 +2,15 @@ package wangsu
 
 import (
 	"context"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	appadomain "github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/appa/domain"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/content"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/deployment"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/domain"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/edgehostname"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/property"
@@ -29,21 +33,40 @@ import (
 	waapWAF "github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap/waf"
 	waapWhitelist "github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap/whitelist"
 	sdkCommon "github.com/wangsu-api/wangsu-sdk-go/wangsu/common"
+	"strings"
+	"time"
 )
 
 const (
-	PROVIDER_SECRET_ID  = "WANGSU_SECRET_ID"
-	PROVIDER_SECRET_KEY = "WANGSU_SECRET_KEY"
-	PROVIDER_PROTOCOL   = "WANGSU_PROTOCOL"
-	PROVIDER_DOMAIN     = "WANGSU_DOMAIN"
+	PROVIDER_SECRET_ID               = "WANGSU_SECRET_ID"
+	PROVIDER_SECRET_KEY              = "WANGSU_SECRET_KEY"
+	PROVIDER_PROTOCOL                = "WANGSU_PROTOCOL"
+	PROVIDER_DOMAIN                  = "WANGSU_DOMAIN"
+	PROVIDER_SITE                    = "WANGSU_SITE"
+	PROVIDER_SHARED_CREDENTIALS_FILE = "WANGSU_SHARED_CREDENTIALS_FILE"
+	PROVIDER_PROFILE                 = "WANGSU_PROFILE"
+	PROVIDER_MAX_RETRIES             = "WANGSU_MAX_RETRIES"
+	PROVIDER_RETRY_MAX_WAIT          = "WANGSU_RETRY_MAX_WAIT"
+	PROVIDER_REQUESTS_PER_SECOND     = "WANGSU_REQUESTS_PER_SECOND"
+	PROVIDER_BURST                   = "WANGSU_BURST"
+	PROVIDER_SKIP_CREDS_VALIDATION   = "WANGSU_SKIP_CREDENTIALS_VALIDATION"
+	PROVIDER_CA_BUNDLE_FILE          = "WANGSU_CA_BUNDLE_FILE"
+	PROVIDER_INSECURE_SKIP_VERIFY    = "WANGSU_INSECURE_SKIP_VERIFY"
+	PROVIDER_HTTP_PROXY              = "WANGSU_HTTP_PROXY"
+	PROVIDER_REQUEST_TIMEOUT         = "WANGSU_REQUEST_TIMEOUT"
+	PROVIDER_USER_AGENT_SUFFIX       = "WANGSU_USER_AGENT_SUFFIX"
 )
 
+// ProviderVersion is the version reported in the User-Agent of the API requests,
+// main sets it from the version injected at build time.
+var ProviderVersion = "dev"
+
 type WangSuClient struct {
 	apiV3Conn *connectivity.WangSuClient
 }
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"secret_id": {
 				Type:        schema.TypeString,
@@ -61,7 +84,7 @@ func Provider() *schema.Provider {
 			"protocol": {
 				Type:         schema.TypeString,
 				Optional:     true,
-				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_PROTOCOL, "https"),
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_PROTOCOL, nil),
 				ValidateFunc: wangsuCommon.ValidateAllowedStringValue([]string{"http", "https"}),
 				Description:  "(Optional)The protocol of the API request. Valid values: `http` and `https`. Default is `https`.",
 			},
@@ -71,17 +94,139 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc(PROVIDER_DOMAIN, nil),
 				Description: "(Optional)The root domain of the API request.Default is `open.chinanetcenter.com`. It is optional",
 			},
+			"site": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_SITE, nil),
+				ValidateFunc: wangsuCommon.ValidateAllowedStringValue(connectivity.Sites),
+				Description:  "(Optional)The console site of the account, which picks the default API domain when `domain` is not set. Valid values: `cn` (mainland, `open.chinanetcenter.com`) and `intl` (international, `open.cdnetworks.com`). Default is `cn`. It can also be sourced from the `WANGSU_SITE` environment variable.",
+			},
 			"service_type": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "(Optional)Security service type. Please enter a specific service type, if you purchase multiple security services.",
 			},
+			"shared_credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(PROVIDER_SHARED_CREDENTIALS_FILE, nil),
+				Description: "(Optional)The path to the shared credentials file. Default is `~/.wangsu/credentials`. It can also be sourced from the `WANGSU_SHARED_CREDENTIALS_FILE` environment variable.",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(PROVIDER_PROFILE, nil),
+				Description: "(Optional)The profile of the shared credentials file to use. Default is `default`. It can also be sourced from the `WANGSU_PROFILE` environment variable.",
+			},
+			"endpoints": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "(Optional)Per-service base URLs, such as `https://gateway.example.com:8443`, that replace `protocol` and `domain` for the requests of that service.",
+				Elem:        endpointsSchema(),
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(PROVIDER_SKIP_CREDS_VALIDATION, true),
+				Description: "(Optional)Skip validating the credentials with an API request when the provider is configured. Set it to `false` to report invalid credentials before any resource is planned. Default is `true`, so that plans and validation without credentials or network access keep working. It can also be sourced from the `WANGSU_SKIP_CREDENTIALS_VALIDATION` environment variable.",
+			},
+			"ca_bundle_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(PROVIDER_CA_BUNDLE_FILE, nil),
+				Description: "(Optional)Path to a PEM file of CA certificates trusted in addition to the system ones, for example the CA of a TLS-intercepting proxy. It applies to the requests the provider sends with its own HTTP client, not to the SDK's. It can also be sourced from the `WANGSU_CA_BUNDLE_FILE` environment variable.",
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(PROVIDER_INSECURE_SKIP_VERIFY, false),
+				Description: "(Optional)Skip the verification of the API server certificate. Only meant for tests against a local stand-in. It applies to the requests the provider sends with its own HTTP client, not to the SDK's. Default is `false`. It can also be sourced from the `WANGSU_INSECURE_SKIP_VERIFY` environment variable.",
+			},
+			"http_proxy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_HTTP_PROXY, nil),
+				ValidateFunc: validateHTTPProxy,
+				Description:  "(Optional)URL of the proxy the API requests go through, such as `http://proxy.example.com:3128`. When not set the `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` environment variables apply. It applies to the requests the provider sends with its own HTTP client, not to the SDK's. It can also be sourced from the `WANGSU_HTTP_PROXY` environment variable.",
+			},
+			"request_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_REQUEST_TIMEOUT, 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "(Optional)The maximum number of seconds an API request may take, reading the response included. It applies to the requests the provider sends with its own HTTP client, not to the SDK's. `0` means no limit. Default is `0`. It can also be sourced from the `WANGSU_REQUEST_TIMEOUT` environment variable.",
+			},
+			"user_agent_suffix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(PROVIDER_USER_AGENT_SUFFIX, nil),
+				Description: "(Optional)A token appended to the User-Agent of the API requests the provider sends with its own HTTP client, for example to tell pipelines apart. It can also be sourced from the `WANGSU_USER_AGENT_SUFFIX` environment variable.",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_MAX_RETRIES, connectivity.DefaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "(Optional)The maximum number of times an API call is retried when the API refused it without processing it: HTTP 429, HTTP 503 or a connection that could not be established. Set to `0` to disable retries. Default is `5`. It can also be sourced from the `WANGSU_MAX_RETRIES` environment variable.",
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_RETRY_MAX_WAIT, int(connectivity.DefaultRetryMaxWait/time.Second)),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "(Optional)The maximum number of seconds to wait between two retries of an API call. Default is `30`. It can also be sourced from the `WANGSU_RETRY_MAX_WAIT` environment variable.",
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_REQUESTS_PER_SECOND, 0.0),
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "(Optional)The maximum number of API requests per second, shared by all services. `0` disables rate limiting. Default is `0`. It can also be sourced from the `WANGSU_REQUESTS_PER_SECOND` environment variable.",
+			},
+			"burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc(PROVIDER_BURST, 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "(Optional)The number of API requests that may be sent at once before `requests_per_second` applies. Default is `requests_per_second` rounded up. It can also be sourced from the `WANGSU_BURST` environment variable.",
+			},
+			"service_rate_limits": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "(Optional)Rate limits of individual services, replacing `requests_per_second` and `burst` for the requests of that service.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"service": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: wangsuCommon.ValidateAllowedStringValue(connectivity.EndpointServices),
+							Description:  "The service, one of the keys of the `endpoints` block.",
+						},
+						"requests_per_second": {
+							Type:         schema.TypeFloat,
+							Required:     true,
+							ValidateFunc: validation.FloatAtLeast(0),
+							Description:  "The maximum number of API requests per second of the service. `0` disables rate limiting for the service.",
+						},
+						"burst": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "The number of API requests of the service that may be sent at once. Default is `requests_per_second` rounded up.",
+						},
+					},
+				},
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"wangsu_cdn_domain":                      domain.ResourceCdnDomain(),
 			"wangsu_cdn_property":                    property.ResourceCdnProperty(),
 			"wangsu_cdn_property_deployment":         property.ResourceCdnPropertyDeployment(),
 			"wangsu_cdn_edge_hostname":               edgehostname.ResourceCdnEdgeHostname(),
+			"wangsu_cdn_deployment_wait":             deployment.ResourceCdnDeploymentWait(),
+			"wangsu_cdn_purge":                       content.ResourceCdnPurge(),
+			"wangsu_cdn_prefetch":                    content.ResourceCdnPrefetch(),
 			"wangsu_ssl_certificate":                 certificate.ResourceSslCertificate(),
 			"wangsu_ssl_certificate_application":     certificateapplication.ResourceSslCertificateApplication(),
 			"wangsu_appa_domain":                     appadomain.ResourceAppaDomain(),
@@ -111,6 +256,7 @@ func Provider() *schema.Provider {
 		DataSourcesMap: map[string]*schema.Resource{
 			"wangsu_cdn_domains":                      domain.DataSourceWangSuCdnDomains(),
 			"wangsu_cdn_domain_detail":                domain.DataSourceWangSuCdnDomainDetail(),
+			"wangsu_cdn_signed_url":                   domain.DataSourceWangSuCdnSignedUrl(),
 			"wangsu_cdn_properties":                   property.DataSourceWangsuCdnProperties(),
 			"wangsu_cdn_property_detail":              property.DataSourceWangSuCdnPropertyDetail(),
 			"wangsu_cdn_property_deployment_detail":   property.DataSourceWangSuCdnPropertyDeploymentDetail(),
@@ -143,16 +289,24 @@ func Provider() *schema.Provider {
 			"wangsu_iam_user_detail":                  user.ResourceIamUserDetail(),
 			"wangsu_iam_users":                        user.ResourceIamUsers(),
 		},
-		ConfigureContextFunc: providerConfigure,
 	}
+	wangsuCommon.AddResourceTypes(provider.ResourcesMap)
+	wangsuCommon.AddResourceTypes(provider.DataSourcesMap)
+	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		// TerraformVersion is only known once Terraform configures the provider
+		userAgent := connectivity.UserAgent(ProviderVersion, provider.TerraformVersion)
+		return providerConfigure(ctx, d, userAgent)
+	}
+	return provider
 }
 
-func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData, userAgent string) (interface{}, diag.Diagnostics) {
 	var (
 		secretId    string
 		secretKey   string
 		protocol    string
 		domain      string
+		site        string
 		serviceType string
 	)
 	if v, ok := d.GetOk("secret_id"); ok {
@@ -170,17 +324,159 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		domain = v.(string)
 	}
 
+	if v, ok := d.GetOk("site"); ok {
+		site = v.(string)
+	}
+
 	if v, ok := d.GetOk("service_type"); ok {
 		serviceType = v.(string)
 	}
 
+	sharedCredentialsFile, hasSharedCredentialsFile := d.GetOk("shared_credentials_file")
+	profile, hasProfile := d.GetOk("profile")
+	sharedProfile, err := connectivity.LoadSharedProfile(sharedCredentialsFile.(string), profile.(string), hasSharedCredentialsFile || hasProfile)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	// arguments and environment variables take precedence over the shared credentials file
+	if sharedProfile != nil {
+		if secretId == "" {
+			secretId = sharedProfile.SecretId
+		}
+		if secretKey == "" {
+			secretKey = sharedProfile.SecretKey
+		}
+		if protocol == "" {
+			protocol = sharedProfile.Protocol
+		}
+		if domain == "" {
+			domain = sharedProfile.Domain
+		}
+		if site == "" {
+			site = sharedProfile.Site
+		}
+		if serviceType == "" {
+			serviceType = sharedProfile.ServiceType
+		}
+	}
+	if protocol == "" {
+		protocol = "https"
+	}
+	// an explicit domain wins over the site's default one
+	if domain == "" && site != "" {
+		domain = connectivity.SiteDomain(site)
+	}
+	if !d.Get("skip_credentials_validation").(bool) {
+		var missing []string
+		if secretId == "" {
+			missing = append(missing, "secret_id")
+		}
+		if secretKey == "" {
+			missing = append(missing, "secret_key")
+		}
+		if len(missing) > 0 {
+			return nil, diag.Errorf("missing %s: set it in the provider block, through the WANGSU_SECRET_ID/WANGSU_SECRET_KEY environment variables or in the shared credentials file", strings.Join(missing, " and "))
+		}
+	}
+
+	endpoints := map[string]string{}
+	if v, ok := d.GetOk("endpoints"); ok {
+		if endpointsMap, ok := v.([]interface{})[0].(map[string]interface{}); ok {
+			for service, endpoint := range endpointsMap {
+				if endpoint.(string) != "" {
+					endpoints[service] = endpoint.(string)
+				}
+			}
+		}
+	}
+
+	serviceRateLimits := map[string]connectivity.RateLimit{}
+	for _, v := range d.Get("service_rate_limits").([]interface{}) {
+		serviceRateLimit := v.(map[string]interface{})
+		service := serviceRateLimit["service"].(string)
+		if _, ok := serviceRateLimits[service]; ok {
+			return nil, diag.Errorf("service_rate_limits: duplicate service %q", service)
+		}
+		serviceRateLimits[service] = connectivity.RateLimit{
+			RequestsPerSecond: serviceRateLimit["requests_per_second"].(float64),
+			Burst:             serviceRateLimit["burst"].(int),
+		}
+	}
+
 	var wangSuClient WangSuClient
 	wangSuClient.apiV3Conn = &connectivity.WangSuClient{
 		Credential:  sdkCommon.NewCredential(secretId, secretKey),
-		HttpProfile: sdkCommon.NewHttpProfile(domain, protocol, serviceType),
+		Domain:      domain,
+		Protocol:    protocol,
+		ServiceType: serviceType,
+		Endpoints:   endpoints,
+		RetryPolicy: connectivity.RetryPolicy{
+			MaxRetries: d.Get("max_retries").(int),
+			MaxWait:    time.Duration(d.Get("retry_max_wait").(int)) * time.Second,
+		},
+		HTTPOptions: connectivity.HTTPOptions{
+			CABundleFile:       d.Get("ca_bundle_file").(string),
+			InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+			HTTPProxy:          d.Get("http_proxy").(string),
+			RequestTimeout:     time.Duration(d.Get("request_timeout").(int)) * time.Second,
+		},
+		UserAgent:       userAgent,
+		UserAgentSuffix: d.Get("user_agent_suffix").(string),
+		RateLimit: connectivity.RateLimit{
+			RequestsPerSecond: d.Get("requests_per_second").(float64),
+			Burst:             d.Get("burst").(int),
+		},
+		ServiceRateLimits: serviceRateLimits,
+	}
+	if stopCtx, ok := schema.StopContext(ctx); ok {
+		wangSuClient.apiV3Conn.StopContext = stopCtx
+	}
+	if _, err := wangSuClient.apiV3Conn.HTTPTransport(); err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	if !d.Get("skip_credentials_validation").(bool) {
+		diags = wangsuCommon.ValidateCredentials(ctx, wangSuClient.apiV3Conn)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+	if d.Get("insecure_skip_verify").(bool) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "TLS certificate verification is disabled",
+			Detail:   "insecure_skip_verify is set, the provider does not verify the certificate of the API server. Use ca_bundle_file to trust a private CA instead.",
+		})
 	}
+	return &wangSuClient, diags
+}
+
+func endpointsSchema() *schema.Resource {
+	endpointSchema := map[string]*schema.Schema{}
+	for _, service := range connectivity.EndpointServices {
+		endpointSchema[service] = &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateEndpoint,
+			Description:  fmt.Sprintf("(Optional)Base URL of the `%s` service API.", service),
+		}
+	}
+	return &schema.Resource{Schema: endpointSchema}
+}
 
-	return &wangSuClient, nil
+func validateHTTPProxy(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := connectivity.ParseHTTPProxy(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %w", k, err))
+	}
+	return
+}
+
+func validateEndpoint(v interface{}, k string) (ws []string, errors []error) {
+	if _, _, err := connectivity.ParseEndpoint(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %w", k, err))
+	}
+	return
 }
 
 // GetAPIV3Conn 返回访问云 API 的客户端连接对象
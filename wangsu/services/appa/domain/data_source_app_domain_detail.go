@@ -5,6 +5,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	appadomain "github.com/wangsu-api/wangsu-sdk-go/wangsu/appa/domain"
 	"golang.org/x/net/context"
 	"log"
@@ -223,7 +224,15 @@ func dataSourceAppaDomainDetailRead(context context.Context, data *schema.Resour
 	var diags diag.Diagnostics
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseAppaDomainClient().QueryAppaDomain(domainName)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseAppaDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointAppa, "QueryAppaDomain", func() (string, error) {
+			requestId, response, err = client.QueryAppaDomain(domainName)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -231,7 +240,7 @@ func dataSourceAppaDomainDetailRead(context context.Context, data *schema.Resour
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -2,11 +2,12 @@ package appadomain
 
 import (
 	"errors"
-	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
+	cdnDomain "github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/domain"
 	appadomain "github.com/wangsu-api/wangsu-sdk-go/wangsu/appa/domain"
 	cdn "github.com/wangsu-api/wangsu-sdk-go/wangsu/cdn/domain"
 	"golang.org/x/net/context"
@@ -23,7 +24,13 @@ func ResourceAppaDomain() *schema.Resource {
 		UpdateContext: resourceAppaDomainUpdate,
 		DeleteContext: resourceAppaDomainDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceAppaDomainImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(time.Duration(QueryDeployResultTimeoutMinutes) * time.Minute),
+			Update: schema.DefaultTimeout(time.Duration(QueryDeployResultTimeoutMinutes) * time.Minute),
+			Delete: schema.DefaultTimeout(time.Duration(QueryDeployResultTimeoutMinutes) * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -110,12 +117,33 @@ func ResourceAppaDomain() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"wait_for_deployment": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether create and update wait until the configuration is deployed. When false they return once the request is accepted, `deploy_status` reports the progress and `wangsu_cdn_deployment_wait` can wait for it later. Default is true.",
+			},
+			"deploy_request_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the request that started the deployment of the last create or update.",
+			},
+			"deploy_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Status of the deployment of the last create or update, such as DEPLOYING, SUCCESS or FAIL. It is refreshed on read until the deployment finishes.",
+			},
 		},
 	}
 }
 
 func resourceAppaDomainCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_appa_domain.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain_name").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &appadomain.AddAppaDomainForTerraformRequest{}
@@ -196,15 +224,23 @@ func resourceAppaDomainCreate(context context.Context, data *schema.ResourceData
 	var addAppaDomainResponse *appadomain.AddAppaDomainForTerraformResponse
 	var requestId string
 	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, addAppaDomainResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseAppaDomainClient().AddAppaDomain(request)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseAppaDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointAppa, "AddAppaDomain", func() (string, error) {
+			requestId, addAppaDomainResponse, err = client.AddAppaDomain(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if addAppaDomainResponse == nil {
@@ -213,33 +249,25 @@ func resourceAppaDomainCreate(context context.Context, data *schema.ResourceData
 	}
 
 	data.SetId(*request.DomainName)
+	_ = data.Set("deploy_request_id", requestId)
+	_ = data.Set("deploy_status", "")
 
-	time.Sleep(3 * time.Second)
 	//query domain deployment status
-	var response *cdn.QueryDeployResultForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(QueryDeployResultTimeoutMinutes)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryDomainDeployStatus(requestId)
+	if data.Get("wait_for_deployment").(bool) {
+		response, err := cdnDomain.WaitForDomainDeployment(context, meta, requestId, data.Timeout(schema.TimeoutCreate))
+
 		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if response != nil && response.Data != nil && *response.Data.DeployResult != "SUCCESS" {
-			return resource.RetryableError(fmt.Errorf("domain deployment status is in progress, retrying"))
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
 		}
-		return nil
-	})
 
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
-	}
-
-	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		if response == nil || response.Data == nil {
+			data.SetId("")
+			return nil
+		}
 	}
 
 	log.Printf("resource.wangsu_appa_domain.create success")
-	_ = data.Set("xCncRequestId", response.Data.RequestId)
 	//set status
 	return resourceAppaDomainRead(context, data, meta)
 }
@@ -269,8 +297,17 @@ func resourceAppaDomainRead(context context.Context, data *schema.ResourceData,
 	var diags diag.Diagnostics
 	var response *appadomain.QueryAppaDomainForTerraformResponse
 	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseAppaDomainClient().QueryAppaDomain(data.Id())
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutRead), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseAppaDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointAppa, "QueryAppaDomain", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.QueryAppaDomain(data.Id())
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -278,18 +315,15 @@ func resourceAppaDomainRead(context context.Context, data *schema.ResourceData,
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		if wangsuCommon.IsNotFound(err) {
+			return wangsuCommon.RemoveFromState(data, "wangsu_appa_domain")
+		}
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
-	if response == nil {
-		data.SetId("")
-		return nil
-	}
-
-	if response.Data == nil {
-		data.SetId("")
-		return nil
+	if response == nil || response.Data == nil {
+		return wangsuCommon.RemoveFromState(data, "wangsu_appa_domain")
 	}
 	_ = data.Set("domain_id", response.Data.DomainId)
 	_ = data.Set("domain_name", response.Data.DomainName)
@@ -301,6 +335,11 @@ func resourceAppaDomainRead(context context.Context, data *schema.ResourceData,
 	_ = data.Set("tcp_ports", response.Data.TcpPorts)
 	_ = data.Set("udp_ports", response.Data.UdpPorts)
 
+	if err := cdnDomain.RefreshDomainDeployStatus(context, data, meta); err != nil {
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+		return diags
+	}
+
 	log.Printf("resource.wangsu_appa_domain.read success")
 	return nil
 }
@@ -326,6 +365,14 @@ func flattenOriginConfig(config []*appadomain.QueryAppaDomainForTerraformRespons
 
 func resourceAppaDomainUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_appa_domain.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain_name").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
+	if !data.HasChangesExcept("wait_for_deployment") {
+		return resourceAppaDomainRead(context, data, meta)
+	}
 	domainName := data.Id()
 	var diags diag.Diagnostics
 	request := &appadomain.UpdateAppaDomainForTerraformRequest{}
@@ -436,15 +483,23 @@ func resourceAppaDomainUpdate(context context.Context, data *schema.ResourceData
 	var updateAppaDomainResponse *appadomain.UpdateAppaDomainForTerraformResponse
 	var requestId string
 	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, updateAppaDomainResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseAppaDomainClient().UpdateAppaDomain(request, domainName)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseAppaDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointAppa, "UpdateAppaDomain", func() (string, error) {
+			requestId, updateAppaDomainResponse, err = client.UpdateAppaDomain(request, domainName)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if updateAppaDomainResponse == nil {
@@ -452,23 +507,17 @@ func resourceAppaDomainUpdate(context context.Context, data *schema.ResourceData
 		return nil
 	}
 
-	time.Sleep(3 * time.Second)
+	_ = data.Set("deploy_request_id", requestId)
+	_ = data.Set("deploy_status", "")
+
 	//query domain deployment status
-	var response *cdn.QueryDeployResultForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(QueryDeployResultTimeoutMinutes)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryDomainDeployStatus(requestId)
+	if data.Get("wait_for_deployment").(bool) {
+		_, err = cdnDomain.WaitForDomainDeployment(context, meta, requestId, data.Timeout(schema.TimeoutUpdate))
+
 		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if response != nil && response.Data != nil && *response.Data.DeployResult != "SUCCESS" {
-			return resource.RetryableError(fmt.Errorf("domain deployment status is in progress, retrying"))
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
 		}
-		return nil
-	})
-
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
 	}
 
 	log.Printf("resource.wangsu_appa_domain.update success")
@@ -477,13 +526,26 @@ func resourceAppaDomainUpdate(context context.Context, data *schema.ResourceData
 
 func resourceAppaDomainDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_appa_domain.delete")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain_name").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *cdn.DeleteDomainForTerraformResponse
 	var requestId string
 	var err error
 	var diags diag.Diagnostics
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().DeleteCdnDomain(data.Id())
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseCdnClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCdn, "DeleteCdnDomain", func() (string, error) {
+			requestId, response, err = client.DeleteCdnDomain(data.Id())
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -491,7 +553,7 @@ func resourceAppaDomainDelete(context context.Context, data *schema.ResourceData
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -499,24 +561,18 @@ func resourceAppaDomainDelete(context context.Context, data *schema.ResourceData
 		return nil
 	}
 
-	time.Sleep(3 * time.Second)
 	//query domain deployment status
-	var deploymentResponse *cdn.QueryDeployResultForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(QueryDeployResultTimeoutMinutes)*time.Minute, func() *resource.RetryError {
-		deploymentResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryDomainDeployStatus(requestId)
-		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if deploymentResponse != nil && deploymentResponse.Data != nil && *deploymentResponse.Data.DeployResult != "SUCCESS" {
-			return resource.RetryableError(fmt.Errorf("domain deployment status is in progress, retrying"))
-		}
-		return nil
-	})
+	_, err = cdnDomain.WaitForDomainDeployment(context, meta, requestId, data.Timeout(schema.TimeoutDelete))
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	log.Printf("resource.wangsu_appa_domain.delete success")
 	return nil
 }
+
+func resourceAppaDomainImport(context context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	_ = data.Set("wait_for_deployment", true)
+	return []*schema.ResourceData{data}, nil
+}
@@ -0,0 +1,57 @@
+package appadomain
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	cdnDomain "github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/domain"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/sweep"
+)
+
+// appaServiceType is the service type of APPA domains in the CDN domain list.
+const appaServiceType = "appa"
+
+func init() {
+	resource.AddTestSweepers("wangsu_appa_domain", &resource.Sweeper{
+		Name: "wangsu_appa_domain",
+		F:    sweepAppaDomains,
+	})
+}
+
+// TestMain runs the sweepers when the tests are started with -sweep, e.g.
+// go test ./wangsu/services/... -sweep=all.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func sweepAppaDomains(region string) error {
+	meta, err := sweep.SharedMeta(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	// APPA domains are listed with the CDN domains, under their service type
+	data, err := sweep.Read(ctx, cdnDomain.DataSourceWangSuCdnDomains(), map[string]interface{}{
+		"service_types": []interface{}{appaServiceType},
+	}, meta)
+	if err != nil {
+		return err
+	}
+
+	var errs sweep.Errors
+	for _, page := range data.Get("data").([]interface{}) {
+		for _, item := range page.(map[string]interface{})["result_list"].([]interface{}) {
+			domainName := item.(map[string]interface{})["domain_name"].(string)
+			if !sweep.HasPrefix(domainName) {
+				continue
+			}
+			log.Printf("[INFO] Deleting APPA domain %s", domainName)
+			errs.Add("APPA domain", domainName, sweep.Delete(ctx, ResourceAppaDomain(), domainName, map[string]interface{}{
+				"domain_name": domainName,
+			}, meta))
+		}
+	}
+	return errs.Err()
+}
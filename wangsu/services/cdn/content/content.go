@@ -0,0 +1,192 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
+)
+
+// The content APIs accept a limited number of URLs and directories per task,
+// longer lists are submitted as several tasks.
+const (
+	maxPurgeUrls    = 1000
+	maxPurgeDirs    = 500
+	maxPrefetchUrls = 400
+)
+
+const (
+	contentStatusSuccess = "SUCCESS"
+	contentStatusFailure = "FAILURE"
+)
+
+// contentTaskStatusFinished is the status of a task whose every URL and
+// directory reached a final status.
+const contentTaskStatusFinished = "FINISHED"
+
+// contentFailureStatuses are the statuses of a URL or directory that failed.
+var contentFailureStatuses = []string{contentStatusFailure, "FAIL", "FAILED"}
+
+// contentApi names the receiver and query paths of a kind of task.
+type contentApi struct {
+	kind        string
+	submitPath  string
+	queryPath   string
+	description string
+}
+
+// The content refresh and prefetch APIs of the content management (CCM) service.
+var (
+	purgeApi = contentApi{
+		kind:        "purge",
+		submitPath:  "/ccm/purge/ItemIdReceiver",
+		queryPath:   "/ccm/purge/ItemIdQuery",
+		description: "purge task",
+	}
+	prefetchApi = contentApi{
+		kind:        "prefetch",
+		submitPath:  "/ccm/fetch/ItemIdReceiver",
+		queryPath:   "/ccm/fetch/ItemIdQuery",
+		description: "prefetch task",
+	}
+)
+
+type purgeRequest struct {
+	Urls      []string `json:"urls,omitempty"`
+	Dirs      []string `json:"dirs,omitempty"`
+	UrlAction string   `json:"urlAction,omitempty"`
+	DirAction string   `json:"dirAction,omitempty"`
+}
+
+type prefetchRequest struct {
+	List []prefetchItem `json:"list"`
+}
+
+type prefetchItem struct {
+	Url string `json:"url"`
+}
+
+type taskQueryRequest struct {
+	ItemId string `json:"itemId"`
+}
+
+// contentResponse is the envelope of the content APIs, whose code is 1 on
+// success. The field names are matched case-insensitively, the receivers answer
+// with Code and Message.
+type contentResponse struct {
+	Code       json.RawMessage     `json:"code"`
+	Message    string              `json:"message"`
+	ItemId     string              `json:"itemId"`
+	ResultList []contentTaskResult `json:"resultList"`
+}
+
+// contentTaskResult is the status of one URL or directory of a task.
+type contentTaskResult struct {
+	Url    string `json:"url"`
+	Status string `json:"status"`
+}
+
+func (r *contentTaskResult) finished() bool {
+	return r.Status == contentStatusSuccess || wangsuCommon.IsContains(contentFailureStatuses, r.Status)
+}
+
+func (r *contentTaskResult) failed() bool {
+	return wangsuCommon.IsContains(contentFailureStatuses, r.Status)
+}
+
+// call sends a request to one of the content APIs and checks the code of its
+// response.
+func (api contentApi) call(ctx context.Context, meta interface{}, path string, request interface{}) (*contentResponse, error) {
+	conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+	client, err := conn.UseContentClient()
+	if err != nil {
+		return nil, err
+	}
+	response := &contentResponse{}
+	var requestId string
+	err = conn.Call(ctx, connectivity.EndpointCdn, path, func() (string, error) {
+		requestId, err = client.Do(ctx, http.MethodPost, path, request, response)
+		return requestId, wangsuCommon.NewAPIError(err, requestId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if code := strings.Trim(string(response.Code), `"`); code != "1" {
+		return nil, &wangsuCommon.APIError{Code: code, Message: response.Message, RequestId: requestId}
+	}
+	return response, nil
+}
+
+// submit submits a task and returns its id.
+func (api contentApi) submit(ctx context.Context, meta interface{}, request interface{}) (string, error) {
+	response, err := api.call(ctx, meta, api.submitPath, request)
+	if err != nil {
+		return "", err
+	}
+	if response.ItemId == "" {
+		return "", fmt.Errorf("the %s API returned no task id", api.kind)
+	}
+	return response.ItemId, nil
+}
+
+// waitForTask polls the task itemId until every URL and directory of it reached
+// a final status, and returns their statuses.
+func (api contentApi) waitForTask(ctx context.Context, meta interface{}, itemId string, timeout time.Duration) ([]contentTaskResult, error) {
+	var results []contentTaskResult
+	waiter := &wangsuCommon.DeployWaiter{
+		Target:      []string{contentTaskStatusFinished},
+		Delay:       3 * time.Second,
+		MinInterval: 5 * time.Second,
+		MaxInterval: 30 * time.Second,
+		Timeout:     timeout,
+		Description: fmt.Sprintf("%s %s", api.description, itemId),
+		Refresh: func(ctx context.Context) (string, string, error) {
+			response, err := api.call(ctx, meta, api.queryPath, &taskQueryRequest{ItemId: itemId})
+			if err != nil {
+				return "", "", err
+			}
+			results = response.ResultList
+			if len(results) == 0 {
+				return "PROCESSING", "", nil
+			}
+			for i := range results {
+				if !results[i].finished() {
+					return "PROCESSING", "", nil
+				}
+			}
+			return contentTaskStatusFinished, "", nil
+		},
+	}
+	_, err := waiter.WaitForStatus(ctx)
+	return results, err
+}
+
+// batches splits items into slices of at most size items.
+func batches(items []string, size int) [][]string {
+	var result [][]string
+	for len(items) > size {
+		result = append(result, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		result = append(result, items)
+	}
+	return result
+}
+
+// expandUrls returns the URLs of a set, sorted so that batches are stable.
+func expandUrls(set *schema.Set) []string {
+	urls := make([]string, 0, set.Len())
+	for _, url := range set.List() {
+		urls = append(urls, url.(string))
+	}
+	sort.Strings(urls)
+	return urls
+}
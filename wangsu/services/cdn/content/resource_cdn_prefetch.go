@@ -0,0 +1,149 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+)
+
+func ResourceCdnPrefetch() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCdnPrefetchCreate,
+		ReadContext:   resourceCdnPrefetchRead,
+		DeleteContext: resourceCdnPrefetchDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Hour),
+		},
+		Schema: map[string]*schema.Schema{
+			"urls": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				ForceNew:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.IsURLWithHTTPorHTTPS},
+				Description: "URLs to prefetch to the edge, such as http://www.example.com/video/launch.mp4. They are submitted in tasks of at most 400 URLs.",
+			},
+			"fail_on_error": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Whether the apply fails when any URL fails to prefetch. The resource is then tainted and prefetches again on the next apply. When false the failed URLs are reported as a warning and in `failed_urls`. Default is false.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values whose change prefetches again, such as the id of a release.",
+			},
+			//computed
+			"task_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Ids of the prefetch tasks, one per batch of URLs.",
+			},
+			"succeeded_urls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "URLs that were prefetched.",
+			},
+			"failed_urls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "URLs that failed to prefetch.",
+			},
+		},
+	}
+}
+
+func resourceCdnPrefetchCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_prefetch.create")
+	var diags diag.Diagnostics
+
+	var taskIds []string
+	for _, batch := range batches(expandUrls(data.Get("urls").(*schema.Set)), maxPrefetchUrls) {
+		request := &prefetchRequest{}
+		for _, url := range batch {
+			request.List = append(request.List, prefetchItem{Url: url})
+		}
+		taskId, err := prefetchApi.submit(context, meta, request)
+		if err != nil {
+			// Keep the tasks submitted so far, the resource is tainted and
+			// prefetches again on the next apply.
+			if len(taskIds) > 0 {
+				data.SetId(resource.UniqueId())
+				_ = data.Set("task_ids", taskIds)
+			}
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+		log.Printf("resource.wangsu_cdn_prefetch.create submitted task %s", taskId)
+		taskIds = append(taskIds, taskId)
+	}
+	// The tasks are recorded before waiting, so that they are in the state
+	// even when the wait fails or times out.
+	data.SetId(resource.UniqueId())
+	_ = data.Set("task_ids", taskIds)
+
+	// All the tasks share the create timeout.
+	deadline := time.Now().Add(data.Timeout(schema.TimeoutCreate))
+	succeeded, failed := []string{}, []string{}
+	for _, taskId := range taskIds {
+		results, err := prefetchApi.waitForTask(context, meta, taskId, time.Until(deadline))
+		if err != nil {
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+		for _, result := range results {
+			if result.failed() {
+				failed = append(failed, result.Url)
+			} else {
+				succeeded = append(succeeded, result.Url)
+			}
+		}
+	}
+	sort.Strings(succeeded)
+	sort.Strings(failed)
+
+	_ = data.Set("succeeded_urls", succeeded)
+	_ = data.Set("failed_urls", failed)
+	if len(failed) > 0 {
+		diagnostic := diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%d of %d URLs failed to prefetch", len(failed), len(failed)+len(succeeded)),
+			Detail:   fmt.Sprintf("Failed URLs: %s", strings.Join(failed, ", ")),
+		}
+		if data.Get("fail_on_error").(bool) {
+			diagnostic.Severity = diag.Error
+		}
+		diags = append(diags, diagnostic)
+	}
+	log.Printf("resource.wangsu_cdn_prefetch.create finished, tasks: %v", taskIds)
+	return diags
+}
+
+// resourceCdnPrefetchRead keeps the state as is: the prefetch has finished when
+// the resource was created.
+func resourceCdnPrefetchRead(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_prefetch.read")
+	return nil
+}
+
+func resourceCdnPrefetchDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_prefetch.delete, remove from state only")
+	data.SetId("")
+	return nil
+}
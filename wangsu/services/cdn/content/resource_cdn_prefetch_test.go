@@ -0,0 +1,66 @@
+package content_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccCdnPrefetch_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+	server.FailUrls("http://tf-acc-test.example.com/missing.mp4")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnPrefetchConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_prefetch.test", "task_ids.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_prefetch.test", "succeeded_urls.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_prefetch.test", "succeeded_urls.0", "http://tf-acc-test.example.com/launch.mp4"),
+					resource.TestCheckResourceAttr("wangsu_cdn_prefetch.test", "failed_urls.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_prefetch.test", "failed_urls.0", "http://tf-acc-test.example.com/missing.mp4"),
+				),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccCdnPrefetch_failOnError(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+	server.FailUrls("http://tf-acc-test.example.com/missing.mp4")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      server.ProviderConfig() + testAccCdnPrefetchConfig(true),
+				ExpectError: regexp.MustCompile(`1 of 2 URLs failed to prefetch`),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccCdnPrefetchConfig(failOnError bool) string {
+	return fmt.Sprintf(`
+resource "wangsu_cdn_prefetch" "test" {
+  urls = [
+    "http://tf-acc-test.example.com/launch.mp4",
+    "http://tf-acc-test.example.com/missing.mp4",
+  ]
+  fail_on_error = %t
+}
+`, failOnError)
+}
@@ -0,0 +1,172 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+)
+
+var purgeActions = []string{"delete", "expire"}
+
+func ResourceCdnPurge() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCdnPurgeCreate,
+		ReadContext:   resourceCdnPurgeRead,
+		DeleteContext: resourceCdnPurgeDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"urls": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ForceNew:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.IsURLWithHTTPorHTTPS},
+				AtLeastOneOf: []string{"urls", "dirs"},
+				Description:  "URLs to purge, such as http://www.example.com/index.html. More URLs than one task accepts are submitted as several tasks.",
+			},
+			"dirs": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ForceNew:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.IsURLWithHTTPorHTTPS},
+				AtLeastOneOf: []string{"urls", "dirs"},
+				Description:  "Directories to purge, ending with a slash, such as http://www.example.com/static/.",
+			},
+			"url_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(purgeActions, false),
+				Description:  "How the URLs are purged, the optional values are delete and expire. The API default applies when it is not set.",
+			},
+			"dir_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(purgeActions, false),
+				Description:  "How the directories are purged, the optional values are delete and expire. The API default applies when it is not set.",
+			},
+			"fail_on_error": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: "Whether the apply fails when any URL or directory fails to purge. The resource is then tainted and purges again on the next apply. When false the failed URLs and directories are reported as a warning and in `failed_urls`. Default is true.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values whose change purges again, such as the id of a release.",
+			},
+			//computed
+			"task_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Ids of the purge tasks, one per submission.",
+			},
+			"failed_urls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "URLs and directories that failed to purge.",
+			},
+		},
+	}
+}
+
+func resourceCdnPurgeCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_purge.create")
+	var diags diag.Diagnostics
+
+	urlBatches := batches(expandUrls(data.Get("urls").(*schema.Set)), maxPurgeUrls)
+	dirBatches := batches(expandUrls(data.Get("dirs").(*schema.Set)), maxPurgeDirs)
+	var taskIds []string
+	for i := 0; i < len(urlBatches) || i < len(dirBatches); i++ {
+		request := &purgeRequest{
+			UrlAction: data.Get("url_action").(string),
+			DirAction: data.Get("dir_action").(string),
+		}
+		if i < len(urlBatches) {
+			request.Urls = urlBatches[i]
+		}
+		if i < len(dirBatches) {
+			request.Dirs = dirBatches[i]
+		}
+		taskId, err := purgeApi.submit(context, meta, request)
+		if err != nil {
+			// Keep the tasks submitted so far, the resource is tainted and
+			// purges again on the next apply.
+			if len(taskIds) > 0 {
+				data.SetId(resource.UniqueId())
+				_ = data.Set("task_ids", taskIds)
+			}
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+		log.Printf("resource.wangsu_cdn_purge.create submitted task %s", taskId)
+		taskIds = append(taskIds, taskId)
+	}
+	// The tasks are recorded before waiting, so that they are in the state
+	// even when the wait fails or times out.
+	data.SetId(resource.UniqueId())
+	_ = data.Set("task_ids", taskIds)
+
+	// All the tasks share the create timeout.
+	deadline := time.Now().Add(data.Timeout(schema.TimeoutCreate))
+	total, failed := 0, []string{}
+	for _, taskId := range taskIds {
+		results, err := purgeApi.waitForTask(context, meta, taskId, time.Until(deadline))
+		if err != nil {
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+		total += len(results)
+		for _, result := range results {
+			if result.failed() {
+				failed = append(failed, result.Url)
+			}
+		}
+	}
+	sort.Strings(failed)
+
+	_ = data.Set("failed_urls", failed)
+	if len(failed) > 0 {
+		diagnostic := diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%d of %d URLs and directories failed to purge", len(failed), total),
+			Detail:   fmt.Sprintf("Failed URLs and directories: %s", strings.Join(failed, ", ")),
+		}
+		if data.Get("fail_on_error").(bool) {
+			diagnostic.Severity = diag.Error
+		}
+		diags = append(diags, diagnostic)
+	}
+	log.Printf("resource.wangsu_cdn_purge.create finished, tasks: %v", taskIds)
+	return diags
+}
+
+// resourceCdnPurgeRead keeps the state as is: the purge has finished when the
+// resource was created.
+func resourceCdnPurgeRead(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_purge.read")
+	return nil
+}
+
+func resourceCdnPurgeDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_purge.delete, remove from state only")
+	data.SetId("")
+	return nil
+}
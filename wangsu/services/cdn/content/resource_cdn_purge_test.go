@@ -0,0 +1,102 @@
+package content_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccCdnPurge_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnPurgeConfig("v1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_purge.test", "task_ids.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_purge.test", "urls.#", "2"),
+				),
+			},
+			{
+				// a new release purges again
+				Config: server.ProviderConfig() + testAccCdnPurgeConfig("v2"),
+				Check:  resource.TestCheckResourceAttr("wangsu_cdn_purge.test", "triggers.release", "v2"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccCdnPurge_failedUrl(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+	server.FailUrls("http://tf-acc-test.example.com/static/")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      server.ProviderConfig() + testAccCdnPurgeConfig("v1"),
+				ExpectError: regexp.MustCompile(`1 of 3 URLs and directories failed to purge`),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccCdnPurge_failedUrlWarning(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+	server.FailUrls("http://tf-acc-test.example.com/static/")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnPurgeConfigWarning,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_purge.test", "task_ids.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_purge.test", "failed_urls.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_purge.test", "failed_urls.0", "http://tf-acc-test.example.com/static/"),
+				),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccCdnPurgeConfig(release string) string {
+	return fmt.Sprintf(`
+resource "wangsu_cdn_purge" "test" {
+  urls = [
+    "http://tf-acc-test.example.com/index.html",
+    "http://tf-acc-test.example.com/app.js",
+  ]
+  dirs = ["http://tf-acc-test.example.com/static/"]
+
+  triggers = {
+    release = %q
+  }
+}
+`, release)
+}
+
+const testAccCdnPurgeConfigWarning = `
+resource "wangsu_cdn_purge" "test" {
+  urls          = ["http://tf-acc-test.example.com/index.html"]
+  dirs          = ["http://tf-acc-test.example.com/static/"]
+  fail_on_error = false
+}
+`
@@ -0,0 +1,104 @@
+package deployment
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/domain"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/edgehostname"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/property"
+)
+
+func ResourceCdnDeploymentWait() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCdnDeploymentWaitCreate,
+		ReadContext:   resourceCdnDeploymentWaitRead,
+		DeleteContext: resourceCdnDeploymentWaitDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(12 * time.Hour),
+		},
+		Schema: map[string]*schema.Schema{
+			"domain_request_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Deployment request ids to wait for, the `deploy_request_id` of `wangsu_cdn_domain` and `wangsu_appa_domain` resources.",
+			},
+			"edge_hostnames": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Edge hostnames whose deployment to wait for.",
+			},
+			"property_deployment_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "Ids of the property deployments to wait for.",
+			},
+		},
+	}
+}
+
+func resourceCdnDeploymentWaitCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_deployment_wait.create")
+	var diags diag.Diagnostics
+
+	// All the waits share the create timeout.
+	deadline := time.Now().Add(data.Timeout(schema.TimeoutCreate))
+	remaining := func() time.Duration {
+		return time.Until(deadline)
+	}
+
+	for _, requestId := range data.Get("domain_request_ids").([]interface{}) {
+		requestId, _ := requestId.(string)
+		if requestId == "" {
+			continue
+		}
+		if _, err := domain.WaitForDomainDeployment(context, meta, requestId, remaining()); err != nil {
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+	}
+	for _, edgeHostname := range data.Get("edge_hostnames").([]interface{}) {
+		edgeHostname, _ := edgeHostname.(string)
+		if edgeHostname == "" {
+			continue
+		}
+		if err := edgehostname.WaitForEdgeHostnameDeployment(context, meta, edgeHostname, remaining()); err != nil {
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+	}
+	for _, deploymentId := range data.Get("property_deployment_ids").([]interface{}) {
+		if err := property.WaitForPropertyDeployment(context, meta, deploymentId.(int), remaining()); err != nil {
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+	}
+
+	data.SetId(resource.UniqueId())
+	log.Printf("resource.wangsu_cdn_deployment_wait.create success, id: %s", data.Id())
+	return nil
+}
+
+// resourceCdnDeploymentWaitRead keeps the state as is: the deployments have
+// finished when the resource was created.
+func resourceCdnDeploymentWaitRead(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_deployment_wait.read")
+	return nil
+}
+
+func resourceCdnDeploymentWaitDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_deployment_wait.delete, remove from state only")
+	data.SetId("")
+	return nil
+}
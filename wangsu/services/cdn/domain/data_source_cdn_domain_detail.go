@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	cdn "github.com/wangsu-api/wangsu-sdk-go/wangsu/cdn/domain"
 	"log"
 	"time"
@@ -563,6 +564,81 @@ func DataSourceWangSuCdnDomainDetail() *schema.Resource {
 								},
 							},
 						},
+						"compression_settings": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Compression settings, the edge compresses the matched responses for the clients that accept it. Note: 1. Each setting applies to the requests matched by its path, file type or directory conditions. 2. When you need to cancel the compression settings, you can pass in the empty node <compression-settings></compression-settings>, i.e. remove all the blocks.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"data_id": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Add a grid type identifier to indicate a specific group configuration when the client has multiple groups of configurations.",
+									},
+									"compression_types": {
+										Type:        schema.TypeList,
+										Computed:    true,
+										Elem:        &schema.Schema{Type: schema.TypeString},
+										Description: "Compression algorithms, the optional values are gzip and brotli. When both are set, brotli is used for the clients that accept it and gzip for the others.",
+									},
+									"min_file_size": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Minimum size of a compressed file in bytes, smaller responses are sent as is. The default is 0.",
+									},
+									"max_file_size": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Maximum size of a compressed file in bytes, larger responses are sent as is. If it is empty, there is no limit.",
+									},
+									"content_types": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Content-Type of the compressed responses, multiple separated by semicolons, such as text/html;text/css;application/javascript. If it is empty, the default text types are compressed.",
+									},
+									"path_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The url matching mode supports fuzzy regularization. If all matches, the input parameters can be configured as: *",
+									},
+									"except_path_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Exception url matching pattern, support regular. Example: ^https?://[^/]+/.*\\.m3u8",
+									},
+									"custom_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching conditions: specify common types, optional values are all or homepage. 1. all: all files 2. homepage: home page",
+									},
+									"file_type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching conditions: file type, please separate by semicolon, optional values: gif png bmp jpeg jpg html htm shtml mp3 wma flv mp4 wmv zip exe rar css txt ico js swf m3u8 xml f4m bootstarp ts.",
+									},
+									"custom_file_type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Custom file type, separate by semicolon.",
+									},
+									"directory": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Directory, multiple separated by semicolons, such as /test/;/image/",
+									},
+									"ignore_letter_case": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Ignore case, the optional value is true or false, true means to ignore case; false means not to ignore case. The default is true.",
+									},
+									"priority": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Indicates the priority of execution order for multiple sets of configurations. A higher number indicates higher priority. If no parameters are passed, the default value is 10.",
+									},
+								},
+							},
+						},
 						"header_modify_rules": {
 							Type:        schema.TypeList,
 							Computed:    true,
@@ -796,6 +872,191 @@ func DataSourceWangSuCdnDomainDetail() *schema.Resource {
 								},
 							},
 						},
+						"visit_control_rules": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Access control settings: allow or deny requests by referer, client IP and User-Agent. Note: 1. Each rule applies to the requests matched by its path, file type or directory conditions. 2. To cancel the access control settings, remove all the blocks.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"data_id": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Add a grid type identifier to indicate a specific group configuration when the client has multiple groups of configurations.",
+									},
+									"path_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The url matching mode supports fuzzy regularization. If all matches, the input parameters can be configured as: *",
+									},
+									"except_path_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Exception url matching pattern, support regular. Example: ^https?://[^/]+/.*\\.m3u8",
+									},
+									"custom_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching conditions: specify common types, optional values are all or homepage. 1. all: all files 2. homepage: home page",
+									},
+									"file_type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching conditions: file type, please separate by semicolon, optional values: gif png bmp jpeg jpg html htm shtml mp3 wma flv mp4 wmv zip exe rar css txt ico js swf m3u8 xml f4m bootstarp ts.",
+									},
+									"custom_file_type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Custom file type, separate by semicolon.",
+									},
+									"specify_url_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Specify URL. The input parameter does not support the URI format starting with http(s)://",
+									},
+									"directory": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Directory, multiple separated by semicolons, such as /test/;/image/",
+									},
+									"ignore_letter_case": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Ignore case, the optional value is true or false, true means to ignore case; false means not to ignore case. The default is true.",
+									},
+									"control_action": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Action taken on a denied request, the optional values are 403 and 302. 403: return 403 Forbidden; 302: redirect to `rewrite_to`. The default is 403.",
+									},
+									"rewrite_to": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Redirect URL of the denied requests when `control_action` is 302, such as http://www.example.com/forbidden.html",
+									},
+									"priority": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Indicates the priority of execution order for multiple sets of configurations. A higher number indicates higher priority. If no parameters are passed, the default value is 10.",
+									},
+									"allow_null_referer": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Whether requests without a Referer header are allowed, the optional value is true or false. The default is true. Only applies when `valid_referer` is set.",
+									},
+									"valid_referer": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Referer allow list, only requests with these referers are allowed, multiple separated by semicolons. Domains and regular expressions are supported, such as www.example.com;.*\\.example\\.com. Cannot be set together with `invalid_referer`.",
+									},
+									"invalid_referer": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Referer deny list, requests with these referers are denied, multiple separated by semicolons. Domains and regular expressions are supported. Cannot be set together with `valid_referer`.",
+									},
+									"valid_ips": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "IP allow list, only requests from these client IPs are allowed, multiple separated by semicolons. IPs and CIDR blocks are supported, such as 1.1.1.1;2.2.2.0/24. Cannot be set together with `invalid_ips`.",
+									},
+									"invalid_ips": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "IP deny list, requests from these client IPs are denied, multiple separated by semicolons. IPs and CIDR blocks are supported, such as 1.1.1.1;2.2.2.0/24. Cannot be set together with `valid_ips`.",
+									},
+									"valid_user_agents": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "User-Agent allow list, only requests whose User-Agent matches one of these regular expressions are allowed, multiple separated by semicolons, such as Chrome.*;Firefox.* Cannot be set together with `invalid_user_agents`.",
+									},
+									"invalid_user_agents": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "User-Agent deny list, requests whose User-Agent matches one of these regular expressions are denied, multiple separated by semicolons, such as .*curl.*;.*wget.* Cannot be set together with `valid_user_agents`.",
+									},
+								},
+							},
+						},
+						"url_auth_rules": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Timestamp URL authentication settings, requests without a valid signature or with an expired one are denied. Note: 1. Each rule applies to the requests matched by its path, file type or directory conditions. 2. To cancel the URL authentication settings, remove all the blocks. 3. The `wangsu_cdn_signed_url` data source signs URLs with the same algorithms.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"data_id": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Add a grid type identifier to indicate a specific group configuration when the client has multiple groups of configurations.",
+									},
+									"auth_type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Authentication type, the optional values are typeA, typeB and typeC. typeA: http://domain/path?auth_key={timestamp}-{rand}-{uid}-{md5hash}, md5hash is the MD5 of {path}-{timestamp}-{rand}-{uid}-{key}. typeB: http://domain/{YYYYMMDDHHMM}/{md5hash}/path, the time is UTC+8 and md5hash is the MD5 of {key}{YYYYMMDDHHMM}{path}. typeC: http://domain/{md5hash}/{timestamp}/path, the timestamp is hexadecimal and md5hash is the MD5 of {key}{path}{timestamp}.",
+									},
+									"primary_key": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Sensitive:   true,
+										Description: "Primary authentication key, 6 to 32 letters and digits.",
+									},
+									"backup_key": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Sensitive:   true,
+										Description: "Backup authentication key, a URL signed with it is also accepted, which allows the primary key to be changed without denying the URLs already signed.",
+									},
+									"ttl": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Validity period of a signed URL in seconds, counted from the timestamp in the URL. The default is 1800.",
+									},
+									"ignore_parameters": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Whether the authentication parameters are ignored when caching and removed from back-to-origin requests, the optional value is true or false. The default is true.",
+									},
+									"path_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The url matching mode supports fuzzy regularization. If all matches, the input parameters can be configured as: *",
+									},
+									"except_path_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Exception url matching pattern, support regular. Example: ^https?://[^/]+/.*\\.m3u8",
+									},
+									"custom_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching conditions: specify common types, optional values are all or homepage. 1. all: all files 2. homepage: home page",
+									},
+									"file_type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching conditions: file type, please separate by semicolon, optional values: gif png bmp jpeg jpg html htm shtml mp3 wma flv mp4 wmv zip exe rar css txt ico js swf m3u8 xml f4m bootstarp ts.",
+									},
+									"custom_file_type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Custom file type, separate by semicolon.",
+									},
+									"specify_url_pattern": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Specify URL. The input parameter does not support the URI format starting with http(s)://",
+									},
+									"directory": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Matching condition: Directory, multiple separated by semicolons, such as /test/;/image/",
+									},
+									"priority": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Indicates the priority of execution order for multiple sets of configurations. A higher number indicates higher priority. If no parameters are passed, the default value is 10.",
+									},
+								},
+							},
+						},
 						"back_to_origin_rewrite_rule": {
 							Type:        schema.TypeList,
 							Optional:    true,
@@ -830,7 +1091,15 @@ func dataSourceWangSuCdnDomainDetailRead(context context.Context, data *schema.R
 	var diags diag.Diagnostics
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryCdnDomain(domainName)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseCdnClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCdn, "QueryCdnDomain", func() (string, error) {
+			response, err = client.QueryCdnDomain(domainName)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -838,7 +1107,7 @@ func dataSourceWangSuCdnDomainDetailRead(context context.Context, data *schema.R
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -851,6 +1120,12 @@ func dataSourceWangSuCdnDomainDetailRead(context context.Context, data *schema.R
 		return nil
 	}
 
+	config, err := queryDomainConfig(context, meta, domainName)
+	if err != nil {
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+		return diags
+	}
+
 	_ = data.Set("code", response.Code)
 	_ = data.Set("message", response.Message)
 	var resultList []interface{}
@@ -872,9 +1147,12 @@ func dataSourceWangSuCdnDomainDetailRead(context context.Context, data *schema.R
 		"http_code_cache_rules":       buildHttpCodeCacheRules(response.Data.HttpCodeCacheRules),
 		"ignore_protocol_rules":       buildIgnoreProtocolRules(response.Data.IgnoreProtocolRules),
 		"http2_settings":              buildHttp2Settings(response.Data.Http2Settings),
+		"compression_settings":        flattenCompressionSettings(config, true),
 		"header_modify_rules":         buildHeaderModifyRules(response.Data.HeaderModifyRules),
 		"rewrite_rule_settings":       buildRewriteRuleSettings(response.Data.RewriteRuleSettings),
 		"back_to_origin_rewrite_rule": buildBackToOriginRewriteRule(response.Data.BackToOriginRewriteRule),
+		"visit_control_rules":         flattenVisitControlRules(config, true),
+		"url_auth_rules":              flattenUrlAuthRules(config, nil),
 	}
 	resultList = append(resultList, domainDetail)
 
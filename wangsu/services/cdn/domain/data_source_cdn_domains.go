@@ -5,6 +5,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	cdn "github.com/wangsu-api/wangsu-sdk-go/wangsu/cdn/domain"
 	"golang.org/x/net/context"
 	"log"
@@ -195,7 +196,16 @@ func dataSourceWangSuCdnDomainsRead(context context.Context, data *schema.Resour
 	var diags diag.Diagnostics
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryCdnDomainList(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseCdnClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCdn, "QueryCdnDomainList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.QueryCdnDomainList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -203,7 +213,7 @@ func dataSourceWangSuCdnDomainsRead(context context.Context, data *schema.Resour
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
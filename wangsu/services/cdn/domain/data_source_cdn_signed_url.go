@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+)
+
+func DataSourceWangSuCdnSignedUrl() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceWangSuCdnSignedUrlRead,
+		Schema: map[string]*schema.Schema{
+			"url": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "URL to sign, such as http://www.example.com/video/test.mp4. Its query string is kept.",
+			},
+			"auth_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(urlAuthTypes, false),
+				Description:  "Authentication type configured for the domain, the optional values are:<br/>typeA: `?auth_key=<timestamp>-<rand>-<uid>-<md5>` is appended to the URL<br/>typeB: the path becomes `/<yyyyMMddHHmm>/<md5>/<path>`, the time is in UTC+8<br/>typeC: the path becomes `/<md5>/<hex timestamp>/<path>`.",
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+				Description: "Authentication key, the primary or backup key of the URL authentication configured for the domain.",
+			},
+			"timestamp": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Signing time as a Unix timestamp in seconds, the URL expires once the validity period configured for the domain has passed. It is required so that the URL only changes with the arguments, for example set it from a `time_rotating` resource.",
+			},
+			"rand": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "0",
+				Description: "Random string of typeA URLs, letters and digits. Default is 0.",
+			},
+			"uid": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "0",
+				Description: "User ID of typeA URLs. Default is 0.",
+			},
+			//computed
+			"signed_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The signed URL.",
+			},
+		},
+	}
+}
+
+func dataSourceWangSuCdnSignedUrlRead(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("data_source.wangsu_cdn_signed_url.read")
+
+	signedUrl, err := signUrl(data.Get("url").(string), urlAuthParams{
+		AuthType:  data.Get("auth_type").(string),
+		Key:       data.Get("key").(string),
+		Timestamp: time.Unix(int64(data.Get("timestamp").(int)), 0),
+		Rand:      data.Get("rand").(string),
+		Uid:       data.Get("uid").(string),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_ = data.Set("signed_url", signedUrl)
+	data.SetId(wangsuCommon.DataResourceIdsHash([]string{signedUrl}))
+	return nil
+}
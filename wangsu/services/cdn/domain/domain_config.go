@@ -0,0 +1,388 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
+)
+
+// domainConfig is the part of the configuration of a domain that the domain
+// types of wangsu-sdk-go do not carry. It is read and written with the signed
+// client of the wangsu/http package, on the path of the domain that the SDK's
+// QueryCdnDomain and UpdateCdnDomain use. A nil list is left out of an update,
+// an empty one clears the configuration, like the empty nodes of the cache
+// blocks.
+type domainConfig struct {
+	VisitControlRules   *[]visitControlRule   `json:"visitControlRules,omitempty"`
+	UrlAuthRules        *[]urlAuthRule        `json:"urlAuthRules,omitempty"`
+	CompressionSettings *[]compressionSetting `json:"compressionSettings,omitempty"`
+}
+
+type visitControlRule struct {
+	DataId            int    `json:"dataId,omitempty"`
+	PathPattern       string `json:"pathPattern,omitempty"`
+	ExceptPathPattern string `json:"exceptPathPattern,omitempty"`
+	CustomPattern     string `json:"customPattern,omitempty"`
+	FileType          string `json:"fileType,omitempty"`
+	CustomFileType    string `json:"customFileType,omitempty"`
+	SpecifyUrlPattern string `json:"specifyUrlPattern,omitempty"`
+	Directory         string `json:"directory,omitempty"`
+	IgnoreLetterCase  string `json:"ignoreLetterCase,omitempty"`
+	ControlAction     string `json:"controlAction,omitempty"`
+	RewriteTo         string `json:"rewriteTo,omitempty"`
+	Priority          string `json:"priority,omitempty"`
+	AllowNullReferer  string `json:"allowNullReferer,omitempty"`
+	ValidReferer      string `json:"validReferer,omitempty"`
+	InvalidReferer    string `json:"invalidReferer,omitempty"`
+	ValidIps          string `json:"validIps,omitempty"`
+	InvalidIps        string `json:"invalidIps,omitempty"`
+	ValidUserAgents   string `json:"validUserAgents,omitempty"`
+	InvalidUserAgents string `json:"invalidUserAgents,omitempty"`
+}
+
+type urlAuthRule struct {
+	DataId            int    `json:"dataId,omitempty"`
+	AuthType          string `json:"authType,omitempty"`
+	PrimaryKey        string `json:"primaryKey,omitempty"`
+	BackupKey         string `json:"backupKey,omitempty"`
+	Ttl               string `json:"ttl,omitempty"`
+	IgnoreParameters  string `json:"ignoreParameters,omitempty"`
+	PathPattern       string `json:"pathPattern,omitempty"`
+	ExceptPathPattern string `json:"exceptPathPattern,omitempty"`
+	CustomPattern     string `json:"customPattern,omitempty"`
+	FileType          string `json:"fileType,omitempty"`
+	CustomFileType    string `json:"customFileType,omitempty"`
+	SpecifyUrlPattern string `json:"specifyUrlPattern,omitempty"`
+	Directory         string `json:"directory,omitempty"`
+	Priority          string `json:"priority,omitempty"`
+}
+
+type compressionSetting struct {
+	DataId            int      `json:"dataId,omitempty"`
+	CompressionTypes  []string `json:"compressionTypes,omitempty"`
+	MinFileSize       string   `json:"minFileSize,omitempty"`
+	MaxFileSize       string   `json:"maxFileSize,omitempty"`
+	ContentTypes      string   `json:"contentTypes,omitempty"`
+	PathPattern       string   `json:"pathPattern,omitempty"`
+	ExceptPathPattern string   `json:"exceptPathPattern,omitempty"`
+	CustomPattern     string   `json:"customPattern,omitempty"`
+	FileType          string   `json:"fileType,omitempty"`
+	CustomFileType    string   `json:"customFileType,omitempty"`
+	Directory         string   `json:"directory,omitempty"`
+	IgnoreLetterCase  string   `json:"ignoreLetterCase,omitempty"`
+	Priority          string   `json:"priority,omitempty"`
+}
+
+// domainConfigKeys are the arguments of wangsu_cdn_domain written by
+// updateDomainConfig instead of the SDK's UpdateCdnDomain.
+var domainConfigKeys = []string{"visit_control_rules", "url_auth_rules", "compression_settings"}
+
+// domainConfigResponse is the envelope of the OpenAPI, whose code is 0 on
+// success.
+type domainConfigResponse struct {
+	Code    json.RawMessage `json:"code"`
+	Message string          `json:"message"`
+	Data    *domainConfig   `json:"data"`
+}
+
+func domainConfigPath(domainName string) string {
+	return "/cdn/domain/" + url.PathEscape(domainName)
+}
+
+// queryDomainConfig reads the configuration of the domain that the SDK does not
+// carry.
+func queryDomainConfig(ctx context.Context, meta interface{}, domainName string) (*domainConfig, error) {
+	response, _, err := callDomainConfig(ctx, meta, "QueryCdnDomainConfig", http.MethodGet, domainName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return &domainConfig{}, nil
+	}
+	return response.Data, nil
+}
+
+// updateDomainConfig writes config to the domain and returns the id of the
+// request, whose deployment WaitForDomainDeployment follows.
+func updateDomainConfig(ctx context.Context, meta interface{}, domainName string, config *domainConfig) (string, error) {
+	_, requestId, err := callDomainConfig(ctx, meta, "UpdateCdnDomainConfig", http.MethodPut, domainName, config)
+	return requestId, err
+}
+
+func callDomainConfig(ctx context.Context, meta interface{}, action, method, domainName string, request interface{}) (*domainConfigResponse, string, error) {
+	conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+	client, err := conn.UseCdnDomainConfigClient()
+	if err != nil {
+		return nil, "", err
+	}
+	response := &domainConfigResponse{}
+	var requestId string
+	err = conn.Call(ctx, connectivity.EndpointCdn, action, func() (string, error) {
+		requestId, err = client.Do(ctx, method, domainConfigPath(domainName), request, response)
+		return requestId, wangsuCommon.NewAPIError(err, requestId)
+	})
+	if err != nil {
+		return nil, requestId, err
+	}
+	if code := strings.Trim(string(response.Code), `"`); code != "" && code != "0" {
+		return nil, requestId, &wangsuCommon.APIError{Code: code, Message: response.Message, RequestId: requestId}
+	}
+	return response, requestId, nil
+}
+
+// expandDomainConfig returns the configuration to write for the arguments in
+// domainConfigKeys, nil when there is none. On create it holds the blocks that
+// are set, on update the ones that changed, an empty list for a removed block.
+func expandDomainConfig(data *schema.ResourceData, update bool) *domainConfig {
+	config := &domainConfig{}
+	empty := true
+	if !update || data.HasChange("visit_control_rules") {
+		rules := expandVisitControlRules(data.Get("visit_control_rules").([]interface{}))
+		if update || len(rules) > 0 {
+			config.VisitControlRules = &rules
+			empty = false
+		}
+	}
+	if !update || data.HasChange("url_auth_rules") {
+		rules := expandUrlAuthRules(data.Get("url_auth_rules").([]interface{}))
+		if update || len(rules) > 0 {
+			config.UrlAuthRules = &rules
+			empty = false
+		}
+	}
+	if !update || data.HasChange("compression_settings") {
+		settings := expandCompressionSettings(data.Get("compression_settings").([]interface{}))
+		if update || len(settings) > 0 {
+			config.CompressionSettings = &settings
+			empty = false
+		}
+	}
+	if empty {
+		return nil
+	}
+	return config
+}
+
+func expandVisitControlRules(list []interface{}) []visitControlRule {
+	rules := make([]visitControlRule, 0, len(list))
+	for _, v := range list {
+		ruleMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rules = append(rules, visitControlRule{
+			PathPattern:       ruleMap["path_pattern"].(string),
+			ExceptPathPattern: ruleMap["except_path_pattern"].(string),
+			CustomPattern:     ruleMap["custom_pattern"].(string),
+			FileType:          ruleMap["file_type"].(string),
+			CustomFileType:    ruleMap["custom_file_type"].(string),
+			SpecifyUrlPattern: ruleMap["specify_url_pattern"].(string),
+			Directory:         ruleMap["directory"].(string),
+			IgnoreLetterCase:  ruleMap["ignore_letter_case"].(string),
+			ControlAction:     ruleMap["control_action"].(string),
+			RewriteTo:         ruleMap["rewrite_to"].(string),
+			Priority:          ruleMap["priority"].(string),
+			AllowNullReferer:  ruleMap["allow_null_referer"].(string),
+			ValidReferer:      ruleMap["valid_referer"].(string),
+			InvalidReferer:    ruleMap["invalid_referer"].(string),
+			ValidIps:          ruleMap["valid_ips"].(string),
+			InvalidIps:        ruleMap["invalid_ips"].(string),
+			ValidUserAgents:   ruleMap["valid_user_agents"].(string),
+			InvalidUserAgents: ruleMap["invalid_user_agents"].(string),
+		})
+	}
+	return rules
+}
+
+// flattenVisitControlRules returns the rules as the visit_control_rules blocks,
+// with their data_id for the data source.
+func flattenVisitControlRules(config *domainConfig, withDataId bool) []interface{} {
+	visitControlRules := make([]interface{}, 0)
+	if config == nil || config.VisitControlRules == nil {
+		return visitControlRules
+	}
+	for _, rule := range *config.VisitControlRules {
+		visitControlRule := map[string]interface{}{
+			"path_pattern":        rule.PathPattern,
+			"except_path_pattern": rule.ExceptPathPattern,
+			"custom_pattern":      rule.CustomPattern,
+			"file_type":           rule.FileType,
+			"custom_file_type":    rule.CustomFileType,
+			"specify_url_pattern": rule.SpecifyUrlPattern,
+			"directory":           rule.Directory,
+			"ignore_letter_case":  rule.IgnoreLetterCase,
+			"control_action":      rule.ControlAction,
+			"rewrite_to":          rule.RewriteTo,
+			"priority":            rule.Priority,
+			"allow_null_referer":  rule.AllowNullReferer,
+			"valid_referer":       rule.ValidReferer,
+			"invalid_referer":     rule.InvalidReferer,
+			"valid_ips":           rule.ValidIps,
+			"invalid_ips":         rule.InvalidIps,
+			"valid_user_agents":   rule.ValidUserAgents,
+			"invalid_user_agents": rule.InvalidUserAgents,
+		}
+		if withDataId {
+			visitControlRule["data_id"] = rule.DataId
+		}
+		visitControlRules = append(visitControlRules, visitControlRule)
+	}
+	return visitControlRules
+}
+
+func expandUrlAuthRules(list []interface{}) []urlAuthRule {
+	rules := make([]urlAuthRule, 0, len(list))
+	for _, v := range list {
+		ruleMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rules = append(rules, urlAuthRule{
+			AuthType:          ruleMap["auth_type"].(string),
+			PrimaryKey:        ruleMap["primary_key"].(string),
+			BackupKey:         ruleMap["backup_key"].(string),
+			Ttl:               ruleMap["ttl"].(string),
+			IgnoreParameters:  ruleMap["ignore_parameters"].(string),
+			PathPattern:       ruleMap["path_pattern"].(string),
+			ExceptPathPattern: ruleMap["except_path_pattern"].(string),
+			CustomPattern:     ruleMap["custom_pattern"].(string),
+			FileType:          ruleMap["file_type"].(string),
+			CustomFileType:    ruleMap["custom_file_type"].(string),
+			SpecifyUrlPattern: ruleMap["specify_url_pattern"].(string),
+			Directory:         ruleMap["directory"].(string),
+			Priority:          ruleMap["priority"].(string),
+		})
+	}
+	return rules
+}
+
+// flattenUrlAuthRules returns the rules as the url_auth_rules blocks. A key
+// that the API returns empty or masked with asterisks keeps its value in
+// current, the blocks in the state, so that it does not show as a change. The
+// data source, which has no state, passes nil and gets the data_id of the rules.
+func flattenUrlAuthRules(config *domainConfig, current []interface{}) []interface{} {
+	urlAuthRules := make([]interface{}, 0)
+	if config == nil || config.UrlAuthRules == nil {
+		return urlAuthRules
+	}
+	for i, rule := range *config.UrlAuthRules {
+		urlAuthRule := map[string]interface{}{
+			"auth_type":           rule.AuthType,
+			"primary_key":         rule.PrimaryKey,
+			"backup_key":          rule.BackupKey,
+			"ttl":                 rule.Ttl,
+			"ignore_parameters":   rule.IgnoreParameters,
+			"path_pattern":        rule.PathPattern,
+			"except_path_pattern": rule.ExceptPathPattern,
+			"custom_pattern":      rule.CustomPattern,
+			"file_type":           rule.FileType,
+			"custom_file_type":    rule.CustomFileType,
+			"specify_url_pattern": rule.SpecifyUrlPattern,
+			"directory":           rule.Directory,
+			"priority":            rule.Priority,
+		}
+		if current == nil {
+			urlAuthRule["data_id"] = rule.DataId
+		} else if i < len(current) {
+			if currentRule, ok := current[i].(map[string]interface{}); ok {
+				for _, key := range []string{"primary_key", "backup_key"} {
+					if isMaskedKey(urlAuthRule[key].(string)) {
+						urlAuthRule[key] = currentRule[key]
+					}
+				}
+			}
+		}
+		urlAuthRules = append(urlAuthRules, urlAuthRule)
+	}
+	return urlAuthRules
+}
+
+func isMaskedKey(key string) bool {
+	return key == "" || strings.Contains(key, "*")
+}
+
+func expandCompressionSettings(list []interface{}) []compressionSetting {
+	settings := make([]compressionSetting, 0, len(list))
+	for _, v := range list {
+		settingMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var compressionTypes []string
+		for _, compressionType := range settingMap["compression_types"].([]interface{}) {
+			if compressionType, ok := compressionType.(string); ok && compressionType != "" {
+				compressionTypes = append(compressionTypes, compressionType)
+			}
+		}
+		settings = append(settings, compressionSetting{
+			CompressionTypes:  compressionTypes,
+			MinFileSize:       settingMap["min_file_size"].(string),
+			MaxFileSize:       settingMap["max_file_size"].(string),
+			ContentTypes:      settingMap["content_types"].(string),
+			PathPattern:       settingMap["path_pattern"].(string),
+			ExceptPathPattern: settingMap["except_path_pattern"].(string),
+			CustomPattern:     settingMap["custom_pattern"].(string),
+			FileType:          settingMap["file_type"].(string),
+			CustomFileType:    settingMap["custom_file_type"].(string),
+			Directory:         settingMap["directory"].(string),
+			IgnoreLetterCase:  settingMap["ignore_letter_case"].(string),
+			Priority:          settingMap["priority"].(string),
+		})
+	}
+	return settings
+}
+
+// flattenCompressionSettings returns the settings as the compression_settings
+// blocks, with their data_id for the data source.
+func flattenCompressionSettings(config *domainConfig, withDataId bool) []interface{} {
+	compressionSettings := make([]interface{}, 0)
+	if config == nil || config.CompressionSettings == nil {
+		return compressionSettings
+	}
+	for _, setting := range *config.CompressionSettings {
+		compressionSetting := map[string]interface{}{
+			"compression_types":   setting.CompressionTypes,
+			"min_file_size":       setting.MinFileSize,
+			"max_file_size":       setting.MaxFileSize,
+			"content_types":       setting.ContentTypes,
+			"path_pattern":        setting.PathPattern,
+			"except_path_pattern": setting.ExceptPathPattern,
+			"custom_pattern":      setting.CustomPattern,
+			"file_type":           setting.FileType,
+			"custom_file_type":    setting.CustomFileType,
+			"directory":           setting.Directory,
+			"ignore_letter_case":  setting.IgnoreLetterCase,
+			"priority":            setting.Priority,
+		}
+		if withDataId {
+			compressionSetting["data_id"] = setting.DataId
+		}
+		compressionSettings = append(compressionSettings, compressionSetting)
+	}
+	return compressionSettings
+}
+
+// writeDomainConfig sends config after the SDK's create or update, records its
+// request as the one of the last deployment and waits for that deployment
+// unless wait_for_deployment is off. timeoutKey is the timeout of the operation.
+func writeDomainConfig(ctx context.Context, data *schema.ResourceData, meta interface{}, config *domainConfig, timeoutKey string) diag.Diagnostics {
+	requestId, err := updateDomainConfig(ctx, meta, data.Id(), config)
+	if err != nil {
+		return wangsuCommon.DiagnosticsFromError(data, err)
+	}
+	_ = data.Set("deploy_request_id", requestId)
+	_ = data.Set("deploy_status", "")
+	if data.Get("wait_for_deployment").(bool) {
+		if _, err := WaitForDomainDeployment(ctx, meta, requestId, data.Timeout(timeoutKey)); err != nil {
+			return wangsuCommon.DiagnosticsFromError(data, err)
+		}
+	}
+	return nil
+}
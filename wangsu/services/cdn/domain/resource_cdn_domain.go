@@ -7,7 +7,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	cdn "github.com/wangsu-api/wangsu-sdk-go/wangsu/cdn/domain"
 	"log"
 	"time"
@@ -22,7 +24,13 @@ func ResourceCdnDomain() *schema.Resource {
 		UpdateContext: resourceCdnDomainUpdate,
 		DeleteContext: resourceCdnDomainDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceCdnDomainImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(time.Duration(QueryDeployResultTimeoutMinutes) * time.Minute),
+			Update: schema.DefaultTimeout(time.Duration(QueryDeployResultTimeoutMinutes) * time.Minute),
+			Delete: schema.DefaultTimeout(time.Duration(QueryDeployResultTimeoutMinutes) * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -513,6 +521,79 @@ func ResourceCdnDomain() *schema.Resource {
 					},
 				},
 			},
+			"compression_settings": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Compression settings, the edge compresses the matched responses for the clients that accept it. Note: 1. Each setting applies to the requests matched by its path, file type or directory conditions. 2. When you need to cancel the compression settings, you can pass in the empty node <compression-settings></compression-settings>, i.e. remove all the blocks.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"compression_types": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice([]string{"gzip", "brotli"}, false),
+							},
+							Description: "Compression algorithms, the optional values are gzip and brotli. When both are set, brotli is used for the clients that accept it and gzip for the others.",
+						},
+						"min_file_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Minimum size of a compressed file in bytes, smaller responses are sent as is. The default is 0.",
+						},
+						"max_file_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Maximum size of a compressed file in bytes, larger responses are sent as is. If it is empty, there is no limit.",
+						},
+						"content_types": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Content-Type of the compressed responses, multiple separated by semicolons, such as text/html;text/css;application/javascript. If it is empty, the default text types are compressed.",
+						},
+						"path_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The url matching mode supports fuzzy regularization. If all matches, the input parameters can be configured as: *",
+						},
+						"except_path_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Exception url matching pattern, support regular. Example: ^https?://[^/]+/.*\\.m3u8",
+						},
+						"custom_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching conditions: specify common types, optional values are all or homepage. 1. all: all files 2. homepage: home page",
+						},
+						"file_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching conditions: file type, please separate by semicolon, optional values: gif png bmp jpeg jpg html htm shtml mp3 wma flv mp4 wmv zip exe rar css txt ico js swf m3u8 xml f4m bootstarp ts.",
+						},
+						"custom_file_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Custom file type, separate by semicolon.",
+						},
+						"directory": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Directory, multiple separated by semicolons, such as /test/;/image/",
+						},
+						"ignore_letter_case": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Ignore case, the optional value is true or false, true means to ignore case; false means not to ignore case. The default is true.",
+						},
+						"priority": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Indicates the priority of execution order for multiple sets of configurations. A higher number indicates higher priority. If no parameters are passed, the default value is 10.",
+						},
+					},
+				},
+			},
 			"header_modify_rules": {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -755,19 +836,224 @@ func ResourceCdnDomain() *schema.Resource {
 					},
 				},
 			},
+			"url_auth_rules": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Timestamp URL authentication settings, requests without a valid signature or with an expired one are denied. Note: 1. Each rule applies to the requests matched by its path, file type or directory conditions. 2. To cancel the URL authentication settings, remove all the blocks. 3. The `wangsu_cdn_signed_url` data source signs URLs with the same algorithms.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"auth_type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(urlAuthTypes, false),
+							Description:  "Authentication type, the optional values are typeA, typeB and typeC. typeA: http://domain/path?auth_key={timestamp}-{rand}-{uid}-{md5hash}, md5hash is the MD5 of {path}-{timestamp}-{rand}-{uid}-{key}. typeB: http://domain/{YYYYMMDDHHMM}/{md5hash}/path, the time is UTC+8 and md5hash is the MD5 of {key}{YYYYMMDDHHMM}{path}. typeC: http://domain/{md5hash}/{timestamp}/path, the timestamp is hexadecimal and md5hash is the MD5 of {key}{path}{timestamp}.",
+						},
+						"primary_key": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "Primary authentication key, 6 to 32 letters and digits.",
+						},
+						"backup_key": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Backup authentication key, a URL signed with it is also accepted, which allows the primary key to be changed without denying the URLs already signed.",
+						},
+						"ttl": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Validity period of a signed URL in seconds, counted from the timestamp in the URL. The default is 1800.",
+						},
+						"ignore_parameters": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Whether the authentication parameters are ignored when caching and removed from back-to-origin requests, the optional value is true or false. The default is true.",
+						},
+						"path_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The url matching mode supports fuzzy regularization. If all matches, the input parameters can be configured as: *",
+						},
+						"except_path_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Exception url matching pattern, support regular. Example: ^https?://[^/]+/.*\\.m3u8",
+						},
+						"custom_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching conditions: specify common types, optional values are all or homepage. 1. all: all files 2. homepage: home page",
+						},
+						"file_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching conditions: file type, please separate by semicolon, optional values: gif png bmp jpeg jpg html htm shtml mp3 wma flv mp4 wmv zip exe rar css txt ico js swf m3u8 xml f4m bootstarp ts.",
+						},
+						"custom_file_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Custom file type, separate by semicolon.",
+						},
+						"specify_url_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Specify URL. The input parameter does not support the URI format starting with http(s)://",
+						},
+						"directory": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Directory, multiple separated by semicolons, such as /test/;/image/",
+						},
+						"priority": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Indicates the priority of execution order for multiple sets of configurations. A higher number indicates higher priority. If no parameters are passed, the default value is 10.",
+						},
+					},
+				},
+			},
+			"visit_control_rules": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Access control settings: allow or deny requests by referer, client IP and User-Agent. Note: 1. Each rule applies to the requests matched by its path, file type or directory conditions. 2. To cancel the access control settings, remove all the blocks.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The url matching mode supports fuzzy regularization. If all matches, the input parameters can be configured as: *",
+						},
+						"except_path_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Exception url matching pattern, support regular. Example: ^https?://[^/]+/.*\\.m3u8",
+						},
+						"custom_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching conditions: specify common types, optional values are all or homepage. 1. all: all files 2. homepage: home page",
+						},
+						"file_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching conditions: file type, please separate by semicolon, optional values: gif png bmp jpeg jpg html htm shtml mp3 wma flv mp4 wmv zip exe rar css txt ico js swf m3u8 xml f4m bootstarp ts.",
+						},
+						"custom_file_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Custom file type, separate by semicolon.",
+						},
+						"specify_url_pattern": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Specify URL. The input parameter does not support the URI format starting with http(s)://",
+						},
+						"directory": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Matching condition: Directory, multiple separated by semicolons, such as /test/;/image/",
+						},
+						"ignore_letter_case": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Ignore case, the optional value is true or false, true means to ignore case; false means not to ignore case. The default is true.",
+						},
+						"control_action": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Action taken on a denied request, the optional values are 403 and 302. 403: return 403 Forbidden; 302: redirect to `rewrite_to`. The default is 403.",
+						},
+						"rewrite_to": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Redirect URL of the denied requests when `control_action` is 302, such as http://www.example.com/forbidden.html",
+						},
+						"priority": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Indicates the priority of execution order for multiple sets of configurations. A higher number indicates higher priority. If no parameters are passed, the default value is 10.",
+						},
+						"allow_null_referer": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Whether requests without a Referer header are allowed, the optional value is true or false. The default is true. Only applies when `valid_referer` is set.",
+						},
+						"valid_referer": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Referer allow list, only requests with these referers are allowed, multiple separated by semicolons. Domains and regular expressions are supported, such as www.example.com;.*\\.example\\.com. Cannot be set together with `invalid_referer`.",
+						},
+						"invalid_referer": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Referer deny list, requests with these referers are denied, multiple separated by semicolons. Domains and regular expressions are supported. Cannot be set together with `valid_referer`.",
+						},
+						"valid_ips": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "IP allow list, only requests from these client IPs are allowed, multiple separated by semicolons. IPs and CIDR blocks are supported, such as 1.1.1.1;2.2.2.0/24. Cannot be set together with `invalid_ips`.",
+						},
+						"invalid_ips": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "IP deny list, requests from these client IPs are denied, multiple separated by semicolons. IPs and CIDR blocks are supported, such as 1.1.1.1;2.2.2.0/24. Cannot be set together with `valid_ips`.",
+						},
+						"valid_user_agents": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "User-Agent allow list, only requests whose User-Agent matches one of these regular expressions are allowed, multiple separated by semicolons, such as Chrome.*;Firefox.* Cannot be set together with `invalid_user_agents`.",
+						},
+						"invalid_user_agents": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "User-Agent deny list, requests whose User-Agent matches one of these regular expressions are denied, multiple separated by semicolons, such as .*curl.*;.*wget.* Cannot be set together with `valid_user_agents`.",
+						},
+					},
+				},
+			},
+			"wait_for_deployment": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether create and update wait until the configuration is deployed. When false they return once the request is accepted, `deploy_status` reports the progress and `wangsu_cdn_deployment_wait` can wait for it later. Default is true.",
+			},
+			"deploy_request_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the request that started the deployment of the last create or update.",
+			},
+			"deploy_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Status of the deployment of the last create or update, such as DEPLOYING, SUCCESS or FAIL. It is refreshed on read until the deployment finishes.",
+			},
 		},
 	}
 }
 
 func resourceCdnDomainDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_cdn_domain.delete")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain_name").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *cdn.DeleteDomainForTerraformResponse
 	var requestId string
 	var err error
 	var diags diag.Diagnostics
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().DeleteCdnDomain(data.Id())
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseCdnClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCdn, "DeleteCdnDomain", func() (string, error) {
+			requestId, response, err = client.DeleteCdnDomain(data.Id())
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -775,7 +1061,7 @@ func resourceCdnDomainDelete(context context.Context, data *schema.ResourceData,
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -783,21 +1069,10 @@ func resourceCdnDomainDelete(context context.Context, data *schema.ResourceData,
 		return nil
 	}
 
-	time.Sleep(3 * time.Second)
 	//query domain deployment status
-	var deploymentResponse *cdn.QueryDeployResultForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(QueryDeployResultTimeoutMinutes)*time.Minute, func() *resource.RetryError {
-		deploymentResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryDomainDeployStatus(requestId)
-		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if deploymentResponse != nil && deploymentResponse.Data != nil && *deploymentResponse.Data.DeployResult != "SUCCESS" {
-			return resource.RetryableError(fmt.Errorf("domain deployment status is in progress, retrying"))
-		}
-		return nil
-	})
+	_, err = WaitForDomainDeployment(context, meta, requestId, data.Timeout(schema.TimeoutDelete))
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -810,8 +1085,16 @@ func resourceCdnDomainRead(context context.Context, data *schema.ResourceData, m
 	var response *cdn.QueryDomainForTerraformResponse
 	var diags diag.Diagnostics
 	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryCdnDomain(data.Id())
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutRead), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseCdnClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCdn, "QueryCdnDomain", func() (string, error) {
+			response, err = client.QueryCdnDomain(data.Id())
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -819,13 +1102,15 @@ func resourceCdnDomainRead(context context.Context, data *schema.ResourceData, m
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		if wangsuCommon.IsNotFound(err) {
+			return wangsuCommon.RemoveFromState(data, "wangsu_cdn_domain")
+		}
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_cdn_domain")
 	}
 	responseData := response.Data
 
@@ -1056,12 +1341,31 @@ func resourceCdnDomainRead(context context.Context, data *schema.ResourceData, m
 		_ = data.Set("back_to_origin_rewrite_rule", []interface{}{backToOriginRewriteRule})
 	}
 
+	config, err := queryDomainConfig(context, meta, data.Id())
+	if err != nil {
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+		return diags
+	}
+	_ = data.Set("visit_control_rules", flattenVisitControlRules(config, false))
+	_ = data.Set("url_auth_rules", flattenUrlAuthRules(config, data.Get("url_auth_rules").([]interface{})))
+	_ = data.Set("compression_settings", flattenCompressionSettings(config, false))
+
+	if err := RefreshDomainDeployStatus(context, data, meta); err != nil {
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+		return diags
+	}
+
 	log.Printf("resource.wangsu_cdn_domain.read success")
 	return nil
 }
 
 func resourceCdnDomainCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_cdn_domain.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain_name").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &cdn.AddDomainForTerraformRequest{}
@@ -1466,15 +1770,23 @@ func resourceCdnDomainCreate(context context.Context, data *schema.ResourceData,
 	var createDomainResponse *cdn.AddDomainForTerraformResponse
 	var requestId string
 	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, createDomainResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().AddCdnDomain(request)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseCdnClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCdn, "AddCdnDomain", func() (string, error) {
+			requestId, createDomainResponse, err = client.AddCdnDomain(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if createDomainResponse == nil {
@@ -1483,39 +1795,45 @@ func resourceCdnDomainCreate(context context.Context, data *schema.ResourceData,
 	}
 
 	data.SetId(*request.DomainName)
+	_ = data.Set("deploy_request_id", requestId)
+	_ = data.Set("deploy_status", "")
 
-	time.Sleep(3 * time.Second)
 	//query domain deployment status
-	var response *cdn.QueryDeployResultForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(QueryDeployResultTimeoutMinutes)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryDomainDeployStatus(requestId)
+	if data.Get("wait_for_deployment").(bool) {
+		response, err := WaitForDomainDeployment(context, meta, requestId, data.Timeout(schema.TimeoutCreate))
+
 		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if response != nil && response.Data != nil && *response.Data.DeployResult != "SUCCESS" {
-			return resource.RetryableError(fmt.Errorf("domain deployment status is in progress, retrying"))
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
 		}
-		return nil
-	})
 
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
+		if response == nil || response.Data == nil {
+			data.SetId("")
+			return nil
+		}
 	}
 
-	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+	if config := expandDomainConfig(data, false); config != nil {
+		if configDiags := writeDomainConfig(context, data, meta, config, schema.TimeoutCreate); configDiags != nil {
+			return configDiags
+		}
 	}
 
 	log.Printf("resource.wangsu_cdn_domain.create success")
-	_ = data.Set("xCncRequestId", response.Data.RequestId)
 	//set status
 	return resourceCdnDomainRead(context, data, meta)
 }
 
 func resourceCdnDomainUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_cdn_domain.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain_name").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
+	if !data.HasChangesExcept("wait_for_deployment") {
+		return resourceCdnDomainRead(context, data, meta)
+	}
 	request := &cdn.UpdateDomainForTerraformRequest{}
 	var diags diag.Diagnostics
 	if data.HasChanges("service_areas") {
@@ -1960,46 +2278,133 @@ func resourceCdnDomainUpdate(context context.Context, data *schema.ResourceData,
 			request.BackToOriginRewriteRule = &cdn.UpdateDomainForTerraformRequestBackToOriginRewriteRule{}
 		}
 	}
-	var editResponse *cdn.UpdateDomainForTerraformResponse
-	var requestId string
-	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, editResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().UpdateCdnDomain(request, data.Id())
+
+	// the blocks the SDK does not carry are written by updateDomainConfig
+	if data.HasChangesExcept(append([]string{"wait_for_deployment"}, domainConfigKeys...)...) {
+		var editResponse *cdn.UpdateDomainForTerraformResponse
+		var requestId string
+		var err error
+		err = resource.RetryContext(context, data.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
+			conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+			client, clientErr := conn.UseCdnClient()
+			if clientErr != nil {
+				return resource.NonRetryableError(clientErr)
+			}
+			err = conn.Call(context, connectivity.EndpointCdn, "UpdateCdnDomain", func() (string, error) {
+				requestId, editResponse, err = client.UpdateCdnDomain(request, data.Id())
+				return requestId, wangsuCommon.NewAPIError(err, requestId)
+			})
+			if err != nil {
+				return resource.NonRetryableError(err)
+			}
+			return nil
+		})
+
 		if err != nil {
-			return resource.NonRetryableError(err)
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
 		}
-		return nil
-	})
 
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
+		if editResponse == nil {
+			data.SetId("")
+			return nil
+		}
+
+		_ = data.Set("deploy_request_id", requestId)
+		_ = data.Set("deploy_status", "")
+
+		//query domain deployment status
+		if data.Get("wait_for_deployment").(bool) {
+			_, err = WaitForDomainDeployment(context, meta, requestId, data.Timeout(schema.TimeoutUpdate))
+			if err != nil {
+				diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+				return diags
+			}
+		}
 	}
 
-	if editResponse == nil {
-		data.SetId("")
+	if data.HasChanges(domainConfigKeys...) {
+		if configDiags := writeDomainConfig(context, data, meta, expandDomainConfig(data, true), schema.TimeoutUpdate); configDiags != nil {
+			return configDiags
+		}
+	}
+
+	log.Printf("resource.wangsu_cdn_domain.update success")
+	return resourceCdnDomainRead(context, data, meta)
+}
+
+func resourceCdnDomainImport(context context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	_ = data.Set("wait_for_deployment", true)
+	return []*schema.ResourceData{data}, nil
+}
+
+// domainDeployFinalStatuses are the statuses of a finished deployment.
+var domainDeployFinalStatuses = []string{"SUCCESS", "FAIL"}
+
+// RefreshDomainDeployStatus sets deploy_status from the deployment of the last
+// create or update, until that deployment has finished. It serves the domains
+// of every product deployed through the CDN API, APPA included.
+func RefreshDomainDeployStatus(ctx context.Context, data *schema.ResourceData, meta interface{}) error {
+	requestId := data.Get("deploy_request_id").(string)
+	if requestId == "" || wangsuCommon.IsContains(domainDeployFinalStatuses, data.Get("deploy_status").(string)) {
 		return nil
 	}
+	response, err := queryDomainDeployResult(ctx, meta, requestId)
+	if err != nil {
+		return err
+	}
+	if response != nil && response.Data != nil && response.Data.DeployResult != nil {
+		_ = data.Set("deploy_status", *response.Data.DeployResult)
+	}
+	return nil
+}
 
-	time.Sleep(3 * time.Second)
+// WaitForDomainDeployment polls the deployment started by the request requestId
+// until it succeeds. A failed deployment is reported at once, with the message of
+// its deploy result as the reason.
+func WaitForDomainDeployment(ctx context.Context, meta interface{}, requestId string, timeout time.Duration) (*cdn.QueryDeployResultForTerraformResponse, error) {
+	var response *cdn.QueryDeployResultForTerraformResponse
+	waiter := &wangsuCommon.DeployWaiter{
+		Target:      []string{"SUCCESS"},
+		Failure:     []string{"FAIL"},
+		Delay:       3 * time.Second,
+		MinInterval: 2 * time.Second,
+		MaxInterval: 10 * time.Second,
+		Timeout:     timeout,
+		Description: fmt.Sprintf("deployment of domain request %s", requestId),
+		Refresh: func(ctx context.Context) (string, string, error) {
+			var err error
+			response, err = queryDomainDeployResult(ctx, meta, requestId)
+			if err != nil {
+				return "", "", err
+			}
+			if response == nil || response.Data == nil || response.Data.DeployResult == nil {
+				return "SUCCESS", "", nil
+			}
+			var reason string
+			if response.Data.Message != nil {
+				reason = *response.Data.Message
+			}
+			return *response.Data.DeployResult, reason, nil
+		},
+	}
+	_, err := waiter.WaitForStatus(ctx)
+	return response, err
+}
 
-	//query domain deployment status
+func queryDomainDeployResult(ctx context.Context, meta interface{}, requestId string) (*cdn.QueryDeployResultForTerraformResponse, error) {
+	conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+	client, err := conn.UseCdnClient()
+	if err != nil {
+		return nil, err
+	}
 	var response *cdn.QueryDeployResultForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(QueryDeployResultTimeoutMinutes)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseCdnClient().QueryDomainDeployStatus(requestId)
-		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if response != nil && response.Data != nil && *response.Data.DeployResult != "SUCCESS" {
-			return resource.RetryableError(fmt.Errorf("domain deployment status is in progress, retrying"))
-		}
-		return nil
+	err = conn.Call(ctx, connectivity.EndpointCdn, "QueryDomainDeployStatus", func() (string, error) {
+		response, err = client.QueryDomainDeployStatus(requestId)
+		return "", wangsuCommon.NewAPIError(err, "")
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
+		return nil, err
 	}
-
-	log.Printf("resource.wangsu_cdn_domain.update success")
-	return resourceCdnDomainRead(context, data, meta)
+	return response, nil
 }
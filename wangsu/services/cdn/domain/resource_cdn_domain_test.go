@@ -0,0 +1,230 @@
+package domain_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccCdnDomain_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy:      testAccCheckCdnDomainDestroy(server),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnDomainConfig("tf-acc-test created"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "id", "tf-acc-test.example.com"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "comment", "tf-acc-test created"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "origin_config.0.origin_ips", "192.0.2.10"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "deploy_status", "SUCCESS"),
+				),
+			},
+			{
+				Config: server.ProviderConfig() + testAccCdnDomainConfig("tf-acc-test updated"),
+				Check:  resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "comment", "tf-acc-test updated"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccCdnDomain_failedDeployment(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy:      testAccCheckCdnDomainDestroy(server),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnDomainConfig("tf-acc-test created"),
+			},
+			{
+				PreConfig:   func() { server.FailNextDeployment("origin 192.0.2.10 is unreachable") },
+				Config:      server.ProviderConfig() + testAccCdnDomainConfig("tf-acc-test updated"),
+				ExpectError: regexp.MustCompile(`failed with status FAIL: origin 192.0.2.10 is unreachable`),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccCdnDomain_visitControlRules(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy:      testAccCheckCdnDomainDestroy(server),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnDomainConfigBlocks(`
+  visit_control_rules {
+    path_pattern       = "/video/.*"
+    allow_null_referer = "false"
+    valid_referer      = "www.example.com"
+    invalid_ips        = "192.0.2.0/24"
+  }
+  visit_control_rules {
+    file_type           = "mp4"
+    invalid_user_agents = ".*curl.*"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "visit_control_rules.#", "2"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "visit_control_rules.0.allow_null_referer", "false"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "visit_control_rules.0.invalid_ips", "192.0.2.0/24"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "visit_control_rules.1.invalid_user_agents", ".*curl.*"),
+				),
+			},
+			{
+				// removing the blocks clears the rules of the domain
+				Config: server.ProviderConfig() + testAccCdnDomainConfigBlocks(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "visit_control_rules.#", "0"),
+					func(*terraform.State) error {
+						doc, _ := server.Collection("cdn_domain").Get("tf-acc-test.example.com")
+						if rules, ok := doc["visitControlRules"].([]interface{}); !ok || len(rules) != 0 {
+							return fmt.Errorf("visitControlRules = %v, want an empty list", doc["visitControlRules"])
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccCdnDomain_urlAuthRules(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy:      testAccCheckCdnDomainDestroy(server),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnDomainConfigBlocks(`
+  url_auth_rules {
+    auth_type   = "typeA"
+    primary_key = "tfAccKey2024"
+    ttl         = "1800"
+    directory   = "/video/"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "url_auth_rules.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "url_auth_rules.0.primary_key", "tfAccKey2024"),
+					func(*terraform.State) error {
+						doc, _ := server.Collection("cdn_domain").Get("tf-acc-test.example.com")
+						if rules, ok := doc["urlAuthRules"].([]interface{}); !ok || len(rules) != 1 {
+							return fmt.Errorf("urlAuthRules = %v, want one rule", doc["urlAuthRules"])
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: server.ProviderConfig() + testAccCdnDomainConfigBlocks(""),
+				Check:  resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "url_auth_rules.#", "0"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccCdnDomain_compressionSettings(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy:      testAccCheckCdnDomainDestroy(server),
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccCdnDomainConfigBlocks(`
+  compression_settings {
+    compression_types = ["brotli", "gzip"]
+    min_file_size     = "1024"
+    content_types     = "text/html;application/javascript"
+    path_pattern      = ".*"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "compression_settings.#", "1"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "compression_settings.0.compression_types.#", "2"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "compression_settings.0.compression_types.0", "brotli"),
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "compression_settings.0.min_file_size", "1024"),
+				),
+			},
+			{
+				// removing the blocks sends an empty list, which clears the settings
+				Config: server.ProviderConfig() + testAccCdnDomainConfigBlocks(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_cdn_domain.test", "compression_settings.#", "0"),
+					func(*terraform.State) error {
+						doc, _ := server.Collection("cdn_domain").Get("tf-acc-test.example.com")
+						if settings, ok := doc["compressionSettings"].([]interface{}); !ok || len(settings) != 0 {
+							return fmt.Errorf("compressionSettings = %v, want an empty list", doc["compressionSettings"])
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccCheckCdnDomainDestroy(server *emulator.Server) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if _, ok := server.Collection("cdn_domain").Get("tf-acc-test.example.com"); ok {
+			return fmt.Errorf("domain tf-acc-test.example.com left after destroy")
+		}
+		return nil
+	}
+}
+
+func testAccCdnDomainConfig(comment string) string {
+	return fmt.Sprintf(`
+resource "wangsu_cdn_domain" "test" {
+  domain_name  = "tf-acc-test.example.com"
+  service_type = "web"
+  comment      = %q
+
+  origin_config {
+    origin_ips = "192.0.2.10"
+  }
+}
+`, comment)
+}
+
+func testAccCdnDomainConfigBlocks(blocks string) string {
+	return fmt.Sprintf(`
+resource "wangsu_cdn_domain" "test" {
+  domain_name  = "tf-acc-test.example.com"
+  service_type = "web"
+
+  origin_config {
+    origin_ips = "192.0.2.10"
+  }
+%s
+}
+`, blocks)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/sweep"
+)
+
+func init() {
+	resource.AddTestSweepers("wangsu_cdn_domain", &resource.Sweeper{
+		Name: "wangsu_cdn_domain",
+		F:    sweepCdnDomains,
+	})
+}
+
+// TestMain runs the sweepers when the tests are started with -sweep, e.g.
+// go test ./wangsu/services/... -sweep=all.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func sweepCdnDomains(region string) error {
+	meta, err := sweep.SharedMeta(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	// without paging arguments the data source returns every domain
+	data, err := sweep.Read(ctx, DataSourceWangSuCdnDomains(), nil, meta)
+	if err != nil {
+		return err
+	}
+
+	var errs sweep.Errors
+	for _, page := range data.Get("data").([]interface{}) {
+		for _, item := range page.(map[string]interface{})["result_list"].([]interface{}) {
+			domain := item.(map[string]interface{})
+			domainName := domain["domain_name"].(string)
+			if !sweep.HasPrefix(domainName) || isAppaDomain(domain) {
+				continue
+			}
+			log.Printf("[INFO] Deleting CDN domain %s", domainName)
+			errs.Add("CDN domain", domainName, sweep.Delete(ctx, ResourceCdnDomain(), domainName, map[string]interface{}{
+				"domain_name": domainName,
+			}, meta))
+		}
+	}
+	return errs.Err()
+}
+
+// isAppaDomain reports whether a domain of the list is an APPA domain, which the
+// wangsu_appa_domain sweeper deletes.
+func isAppaDomain(domain map[string]interface{}) bool {
+	for _, serviceType := range domain["service_types"].([]interface{}) {
+		if serviceType == "appa" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The expected URLs were computed with Python's hashlib from the formulas in
+// the auth_type description of url_auth_rules, independently of signUrl, for
+// the key tfAccKey2024 and the time 1700000000 (2023-11-15 06:13 in UTC+8).
+func TestSignUrl(t *testing.T) {
+	const key = "tfAccKey2024"
+	signingTime := time.Unix(1700000000, 0)
+	cases := []struct {
+		name   string
+		url    string
+		params urlAuthParams
+		want   string
+	}{
+		{
+			name:   "typeA",
+			url:    "http://cdn.example.com/video/standard/1K.html",
+			params: urlAuthParams{AuthType: urlAuthTypeA, Key: key, Timestamp: signingTime, Rand: "0", Uid: "0"},
+			want:   "http://cdn.example.com/video/standard/1K.html?auth_key=1700000000-0-0-45b03e90b4f17270bd8ed1b096b85e9e",
+		},
+		{
+			name:   "typeA with rand and uid",
+			url:    "http://cdn.example.com/video/standard/1K.html",
+			params: urlAuthParams{AuthType: urlAuthTypeA, Key: key, Timestamp: signingTime, Rand: "a1b2", Uid: "1001"},
+			want:   "http://cdn.example.com/video/standard/1K.html?auth_key=1700000000-a1b2-1001-82f1ad2761579418fa39ab06be8dd069",
+		},
+		{
+			name:   "typeA keeps the query string",
+			url:    "http://cdn.example.com/video/standard/1K.html?quality=hd",
+			params: urlAuthParams{AuthType: urlAuthTypeA, Key: key, Timestamp: signingTime, Rand: "0", Uid: "0"},
+			want:   "http://cdn.example.com/video/standard/1K.html?quality=hd&auth_key=1700000000-0-0-45b03e90b4f17270bd8ed1b096b85e9e",
+		},
+		{
+			name:   "typeB",
+			url:    "http://cdn.example.com/4/44/44c0909bcfc20a01afaf256ca99a8b8b.mp3",
+			params: urlAuthParams{AuthType: urlAuthTypeB, Key: key, Timestamp: signingTime},
+			want:   "http://cdn.example.com/202311150613/00287a43b50153f676ca037c06aaddf7/4/44/44c0909bcfc20a01afaf256ca99a8b8b.mp3",
+		},
+		{
+			name:   "typeC",
+			url:    "http://cdn.example.com/test.flv",
+			params: urlAuthParams{AuthType: urlAuthTypeC, Key: key, Timestamp: signingTime},
+			want:   "http://cdn.example.com/abc7bcbc06b95ba128297d65aeacb347/6553F100/test.flv",
+		},
+		{
+			name:   "typeC signs the escaped path",
+			url:    "http://cdn.example.com/video/a%20b.mp4",
+			params: urlAuthParams{AuthType: urlAuthTypeC, Key: key, Timestamp: signingTime},
+			want:   "http://cdn.example.com/bf456ff277bd648520ed1da115222a00/6553F100/video/a%20b.mp4",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := signUrl(c.url, c.params)
+			if err != nil {
+				t.Fatalf("signUrl: %s", err)
+			}
+			if got != c.want {
+				t.Errorf("signUrl(%q) =\n%s\nwant\n%s", c.url, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSignUrlErrors(t *testing.T) {
+	cases := map[string]struct {
+		url      string
+		authType string
+	}{
+		"relative url":     {"/video/test.mp4", urlAuthTypeA},
+		"unknown type":     {"http://cdn.example.com/test.flv", "typeD"},
+		"unparseable url":  {"http://cdn.example.com/%zz", urlAuthTypeA},
+		"missing the host": {"http:///test.flv", urlAuthTypeC},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := signUrl(c.url, urlAuthParams{AuthType: c.authType, Key: "key", Timestamp: time.Unix(1439596800, 0)}); err == nil {
+				t.Errorf("signUrl(%q, %s) succeeded, want an error", c.url, c.authType)
+			}
+		})
+	}
+}
+
+func TestDataSourceWangSuCdnSignedUrlKeyIsSensitive(t *testing.T) {
+	if !DataSourceWangSuCdnSignedUrl().Schema["key"].Sensitive {
+		t.Error("key of wangsu_cdn_signed_url must be sensitive")
+	}
+}
+
+// The signed URL must only change with the arguments, a timestamp taken from the
+// clock would sign a new URL on every read.
+func TestDataSourceWangSuCdnSignedUrlTimestampIsRequired(t *testing.T) {
+	if !DataSourceWangSuCdnSignedUrl().Schema["timestamp"].Required {
+		t.Error("timestamp of wangsu_cdn_signed_url must be required")
+	}
+}
+
+func TestUrlAuthKeysAreSensitive(t *testing.T) {
+	rules := ResourceCdnDomain().Schema["url_auth_rules"].Elem.(*schema.Resource).Schema
+	for _, key := range []string{"primary_key", "backup_key"} {
+		if !rules[key].Sensitive {
+			t.Errorf("url_auth_rules.%s of wangsu_cdn_domain must be sensitive", key)
+		}
+	}
+}
+
+func TestFlattenUrlAuthRulesKeepsMaskedKeys(t *testing.T) {
+	config := &domainConfig{UrlAuthRules: &[]urlAuthRule{{AuthType: urlAuthTypeA, PrimaryKey: "tf****24", BackupKey: ""}}}
+	current := []interface{}{map[string]interface{}{"primary_key": "tfAccKey2024", "backup_key": "tfAccKey2025"}}
+	rule := flattenUrlAuthRules(config, current)[0].(map[string]interface{})
+	if rule["primary_key"] != "tfAccKey2024" || rule["backup_key"] != "tfAccKey2025" {
+		t.Errorf("keys = %v and %v, want the ones of the state", rule["primary_key"], rule["backup_key"])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/edgehostname"
 	"golang.org/x/net/context"
 	"log"
@@ -180,7 +181,15 @@ func dataSourceWangSuCdnEdgeHostnameDetailRead(context context.Context, data *sc
 	var diags diag.Diagnostics
 	var err error
 	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseEdgeHostnameClient().QueryEdgeHostname(edgeHostname)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseEdgeHostnameClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointEdgeHostname, "QueryEdgeHostname", func() (string, error) {
+			response, err = client.QueryEdgeHostname(edgeHostname)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -188,7 +197,7 @@ func dataSourceWangSuCdnEdgeHostnameDetailRead(context context.Context, data *sc
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/edgehostname"
 	"golang.org/x/net/context"
 	"log"
@@ -267,7 +268,15 @@ func dataSourceWangSuCdnEdgeHostnamesRead(context context.Context, data *schema.
 	var err error
 	var requestId string
 	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseEdgeHostnameClient().QueryEdgeHostnames(parameters)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseEdgeHostnameClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointEdgeHostname, "QueryEdgeHostnames", func() (string, error) {
+			requestId, response, err = client.QueryEdgeHostnames(parameters)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -275,7 +284,7 @@ func dataSourceWangSuCdnEdgeHostnamesRead(context context.Context, data *schema.
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/edgehostname"
 	"golang.org/x/net/context"
 	"log"
@@ -19,7 +20,13 @@ func ResourceCdnEdgeHostname() *schema.Resource {
 		UpdateContext: resourceCdnEdgeHostnameUpdate,
 		DeleteContext: resourceCdnEdgeHostnameDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceCdnEdgeHostnameImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(12 * time.Hour),
+			Update: schema.DefaultTimeout(12 * time.Hour),
+			Delete: schema.DefaultTimeout(3 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
 		},
 		Schema: map[string]*schema.Schema{
 			"edge_hostname": {
@@ -93,6 +100,12 @@ func ResourceCdnEdgeHostname() *schema.Resource {
 				Computed:    true,
 				Description: "Deploy status; possible values: [pending, deploying, success, fail].",
 			},
+			"wait_for_deployment": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether create and update wait until the edge-hostname is deployed. When false they return once the deployment is submitted, `deploy_status` reports the progress and `wangsu_cdn_deployment_wait` can wait for it later. Default is true.",
+			},
 		},
 	}
 }
@@ -104,8 +117,16 @@ func resourceCdnEdgeHostnameRead(context context.Context, data *schema.ResourceD
 
 	var response *edgehostname.QueryEdgeHostnameForTerraformResponse
 	var err error
-	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseEdgeHostnameClient().QueryEdgeHostname(edgeHostname)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutRead), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseEdgeHostnameClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointEdgeHostname, "QueryEdgeHostname", func() (string, error) {
+			response, err = client.QueryEdgeHostname(edgeHostname)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -113,13 +134,15 @@ func resourceCdnEdgeHostnameRead(context context.Context, data *schema.ResourceD
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		if wangsuCommon.IsNotFound(err) {
+			return wangsuCommon.RemoveFromState(data, "wangsu_cdn_edge_hostname")
+		}
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_cdn_edge_hostname")
 	}
 
 	if err := data.Set("edge_hostname", response.Data.EdgeHostname); err != nil {
@@ -161,10 +184,10 @@ func resourceCdnEdgeHostnameRead(context context.Context, data *schema.ResourceD
 func resourceCdnEdgeHostnameCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_edge_hostname.create")
 	edgeHostname := data.Get("edge_hostname").(string)
-	return executeUpdate(edgeHostname, context, data, meta)
+	return executeUpdate(edgeHostname, context, data, meta, data.Timeout(schema.TimeoutCreate))
 }
 
-func executeUpdate(edgeHostname string, context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func executeUpdate(edgeHostname string, context context.Context, data *schema.ResourceData, meta interface{}, timeout time.Duration) diag.Diagnostics {
 	var diags diag.Diagnostics
 	request := &edgehostname.UpdateEdgeHostnameForTerraformRequest{}
 	if comment, ok := data.Get("comment").(string); ok && comment != "" {
@@ -204,7 +227,15 @@ func executeUpdate(edgeHostname string, context context.Context, data *schema.Re
 	var response *edgehostname.UpdateEdgeHostnameForTerraformResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseEdgeHostnameClient().UpdateEdgeHostname(edgeHostname, request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseEdgeHostnameClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointEdgeHostname, "UpdateEdgeHostname", func() (string, error) {
+			response, err = client.UpdateEdgeHostname(edgeHostname, request)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -212,7 +243,7 @@ func executeUpdate(edgeHostname string, context context.Context, data *schema.Re
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -221,14 +252,22 @@ func executeUpdate(edgeHostname string, context context.Context, data *schema.Re
 	}
 	var deployResponse *edgehostname.DeployEdgeHostnameForTerraformResponse
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		deployResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseEdgeHostnameClient().DeployEdgeHostname(edgeHostname)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseEdgeHostnameClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointEdgeHostname, "DeployEdgeHostname", func() (string, error) {
+			deployResponse, err = client.DeployEdgeHostname(edgeHostname)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if deployResponse == nil {
@@ -236,34 +275,63 @@ func executeUpdate(edgeHostname string, context context.Context, data *schema.Re
 		return nil
 	}
 
-	var readResponse *edgehostname.QueryEdgeHostnameForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(12)*time.Hour, func() *resource.RetryError {
-		readResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseEdgeHostnameClient().QueryEdgeHostname(edgeHostname)
-		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if readResponse != nil && readResponse.Data != nil && *readResponse.Data.DeployStatus == "fail" {
-			return resource.NonRetryableError(fmt.Errorf("edge-hostname deployment failed"))
-		}
-		if readResponse != nil && readResponse.Data != nil && *readResponse.Data.DeployStatus != "success" {
-			return resource.RetryableError(fmt.Errorf("edge-hostname is in progress, retrying"))
-		}
-		return nil
-	})
+	data.SetId(edgeHostname)
 
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
+	if data.Get("wait_for_deployment").(bool) {
+		if err = WaitForEdgeHostnameDeployment(context, meta, edgeHostname, timeout); err != nil {
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
 	}
 
-	data.SetId(edgeHostname)
-
 	return resourceCdnEdgeHostnameRead(context, data, meta)
 }
 
+func resourceCdnEdgeHostnameImport(context context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	_ = data.Set("wait_for_deployment", true)
+	return []*schema.ResourceData{data}, nil
+}
+
+// WaitForEdgeHostnameDeployment polls an edge-hostname until its deployment
+// succeeds. A failed deployment is reported at once.
+func WaitForEdgeHostnameDeployment(ctx context.Context, meta interface{}, edgeHostname string, timeout time.Duration) error {
+	waiter := &wangsuCommon.DeployWaiter{
+		Target:      []string{"success"},
+		Failure:     []string{"fail"},
+		MinInterval: 2 * time.Second,
+		MaxInterval: 30 * time.Second,
+		Timeout:     timeout,
+		Description: fmt.Sprintf("deployment of edge-hostname %s", edgeHostname),
+		Refresh: func(ctx context.Context) (string, string, error) {
+			conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+			client, err := conn.UseEdgeHostnameClient()
+			if err != nil {
+				return "", "", err
+			}
+			var readResponse *edgehostname.QueryEdgeHostnameForTerraformResponse
+			err = conn.Call(ctx, connectivity.EndpointEdgeHostname, "QueryEdgeHostname", func() (string, error) {
+				readResponse, err = client.QueryEdgeHostname(edgeHostname)
+				return "", wangsuCommon.NewAPIError(err, "")
+			})
+			if err != nil {
+				return "", "", err
+			}
+			if readResponse == nil || readResponse.Data == nil || readResponse.Data.DeployStatus == nil {
+				return "success", "", nil
+			}
+			return *readResponse.Data.DeployStatus, "", nil
+		},
+	}
+	_, err := waiter.WaitForStatus(ctx)
+	return err
+}
+
 func resourceCdnEdgeHostnameUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_edge_hostname.update")
-	return executeUpdate(data.Id(), context, data, meta)
+	if !data.HasChangesExcept("wait_for_deployment") {
+		return resourceCdnEdgeHostnameRead(context, data, meta)
+	}
+	return executeUpdate(data.Id(), context, data, meta, data.Timeout(schema.TimeoutUpdate))
 }
 
 func resourceCdnEdgeHostnameDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -273,8 +341,16 @@ func resourceCdnEdgeHostnameDelete(context context.Context, data *schema.Resourc
 
 	var response *edgehostname.DeleteEdgeHostnameForTerraformResponse
 	var err error
-	err = resource.RetryContext(context, time.Duration(3)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseEdgeHostnameClient().DeleteEdgeHostname(edgeHostname)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseEdgeHostnameClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointEdgeHostname, "DeleteEdgeHostname", func() (string, error) {
+			response, err = client.DeleteEdgeHostname(edgeHostname)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -282,7 +358,7 @@ func resourceCdnEdgeHostnameDelete(context context.Context, data *schema.Resourc
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/propertyconfig"
 	"golang.org/x/net/context"
 	"log"
@@ -284,7 +285,15 @@ func dataSourceWangSuCdnPropertiesRead(context context.Context, data *schema.Res
 	var err error
 	var requestId string
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().QueryProperties(parameters)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "QueryProperties", func() (string, error) {
+			requestId, response, err = client.QueryProperties(parameters)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -292,7 +301,7 @@ func dataSourceWangSuCdnPropertiesRead(context context.Context, data *schema.Res
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -5,6 +5,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/propertyconfig"
 	"golang.org/x/net/context"
 	"log"
@@ -111,7 +112,15 @@ func dataSourceWangSuCdnPropertyDeploymentDetailRead(context context.Context, da
 	var diags diag.Diagnostics
 	var err error
 	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().QueryDeployment(deploymentId)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "QueryDeployment", func() (string, error) {
+			response, err = client.QueryDeployment(deploymentId)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -119,7 +128,7 @@ func dataSourceWangSuCdnPropertyDeploymentDetailRead(context context.Context, da
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
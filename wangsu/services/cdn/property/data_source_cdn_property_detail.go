@@ -5,6 +5,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/propertyconfig"
 	"golang.org/x/net/context"
 	"log"
@@ -345,7 +346,15 @@ func dataSourceWangSuCdnPropertyDetailRead(context context.Context, data *schema
 	var diags diag.Diagnostics
 	var err error
 	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().QueryPropertyVersion(propertyId, version)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "QueryPropertyVersion", func() (string, error) {
+			response, err = client.QueryPropertyVersion(propertyId, version)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -353,7 +362,7 @@ func dataSourceWangSuCdnPropertyDetailRead(context context.Context, data *schema
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
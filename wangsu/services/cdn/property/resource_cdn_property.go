@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	propertyConfig "github.com/wangsu-api/wangsu-sdk-go/wangsu/propertyconfig"
 	"golang.org/x/net/context"
 	"log"
@@ -256,7 +257,15 @@ func resourceCdnPropertyRead(context context.Context, data *schema.ResourceData,
 
 	var response *propertyConfig.QueryPropertyConfigForTerrformResponse
 	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().QueryProperty(propertyId)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "QueryProperty", func() (string, error) {
+			response, err = client.QueryProperty(propertyId)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -264,13 +273,15 @@ func resourceCdnPropertyRead(context context.Context, data *schema.ResourceData,
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		if wangsuCommon.IsNotFound(err) {
+			return wangsuCommon.RemoveFromState(data, "wangsu_cdn_property")
+		}
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_cdn_property")
 	}
 
 	if err := data.Set("service_type", response.Data.ServiceType); err != nil {
@@ -501,14 +512,22 @@ func resourceCdnPropertyCreate(context context.Context, data *schema.ResourceDat
 	var response *propertyConfig.CreatePropertyForTerraformResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().CreateProperty(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "CreateProperty", func() (string, error) {
+			response, err = client.CreateProperty(request)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -518,7 +537,9 @@ func resourceCdnPropertyCreate(context context.Context, data *schema.ResourceDat
 	var propertyIdStr = strconv.FormatInt(*response.Data.PropertyId, 10)
 	data.SetId(propertyIdStr)
 	log.Printf("resource.wangsu_cdn_property.create success, propertyId: %s", propertyIdStr)
-	time.Sleep(1 * time.Second)
+	if err := wangsuCommon.SleepContext(context, 1*time.Second); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 	return resourceCdnPropertyRead(context, data, meta)
 }
 
@@ -636,14 +657,22 @@ func resourceCdnPropertyUpdate(context context.Context, data *schema.ResourceDat
 	}
 	var response *propertyConfig.UpdatePropertyForTerraformResponse
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().UpdateProperty(propertyId, request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "UpdateProperty", func() (string, error) {
+			response, err = client.UpdateProperty(propertyId, request)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -651,7 +680,9 @@ func resourceCdnPropertyUpdate(context context.Context, data *schema.ResourceDat
 		return nil
 	}
 	log.Printf("resource.wangsu_cdn_property.update success, propertyId: %s", data.Id())
-	time.Sleep(1 * time.Second)
+	if err := wangsuCommon.SleepContext(context, 1*time.Second); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 	return resourceCdnPropertyRead(context, data, meta)
 }
 
@@ -665,7 +696,15 @@ func resourceCdnPropertyDelete(context context.Context, data *schema.ResourceDat
 	}
 
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().DeleteProperty(propertyId)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "DeleteProperty", func() (string, error) {
+			_, err = client.DeleteProperty(propertyId)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -673,7 +712,7 @@ func resourceCdnPropertyDelete(context context.Context, data *schema.ResourceDat
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	propertyConfig "github.com/wangsu-api/wangsu-sdk-go/wangsu/propertyconfig"
 	"golang.org/x/net/context"
 	"log"
@@ -17,7 +18,14 @@ func ResourceCdnPropertyDeployment() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceCdnPropertyDeploymentCreate,
 		ReadContext:   resourceCdnPropertyDeploymentRead,
+		UpdateContext: resourceCdnPropertyDeploymentUpdate,
 		DeleteContext: resourceCdnPropertyDeploymentDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(12 * time.Hour),
+			Update: schema.DefaultTimeout(12 * time.Hour),
+			Delete: schema.DefaultTimeout(1 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"deployment_name": {
@@ -71,6 +79,12 @@ func ResourceCdnPropertyDeployment() *schema.Resource {
 				Computed:    true,
 				Description: "Status of Deployment. Enum: PENDING, IN_PROCESS, SUCCESS, FAIL.",
 			},
+			"wait_for_deployment": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether create waits until the deployment finishes; turning it on later makes update wait for it. When false create returns once the deployment task is created, `status` reports the progress and `wangsu_cdn_deployment_wait` can wait for it later. Default is true.",
+			},
 		},
 	}
 }
@@ -84,8 +98,16 @@ func resourceCdnPropertyDeploymentRead(context context.Context, data *schema.Res
 	}
 
 	var response *propertyConfig.QueryDeploymentForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(1)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().QueryDeployment(deploymentId)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutRead), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "QueryDeployment", func() (string, error) {
+			response, err = client.QueryDeployment(deploymentId)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -93,13 +115,15 @@ func resourceCdnPropertyDeploymentRead(context context.Context, data *schema.Res
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		if wangsuCommon.IsNotFound(err) {
+			return wangsuCommon.RemoveFromState(data, "wangsu_cdn_property_deployment")
+		}
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_cdn_property_deployment")
 	}
 
 	if err := data.Set("deployment_name", response.Data.DeploymentName); err != nil {
@@ -169,8 +193,16 @@ func resourceCdnPropertyDeploymentCreate(context context.Context, data *schema.R
 	var diags diag.Diagnostics
 	var response *propertyConfig.CreateDeploymentTaskForTerraformResponse
 	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().CreateDeployment(request)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePropertyConfigClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointPropertyConfig, "CreateDeployment", func() (string, error) {
+			response, err = client.CreateDeployment(request)
+			return "", wangsuCommon.NewAPIError(err, "")
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -178,7 +210,7 @@ func resourceCdnPropertyDeploymentCreate(context context.Context, data *schema.R
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -187,28 +219,71 @@ func resourceCdnPropertyDeploymentCreate(context context.Context, data *schema.R
 	}
 	var deploymentId = *response.Data.DeploymentId
 
-	time.Sleep(2 * time.Second)
+	data.SetId(strconv.Itoa(deploymentId))
+
+	if data.Get("wait_for_deployment").(bool) {
+		if err = WaitForPropertyDeployment(context, meta, deploymentId, data.Timeout(schema.TimeoutCreate)); err != nil {
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+			return diags
+		}
+	}
+	log.Printf("resource.wangsu_cdn_property_deployment.create success, deploymentId: %d", response.Data.DeploymentId)
+	return resourceCdnPropertyDeploymentRead(context, data, meta)
+}
 
-	var deploymentResponse *propertyConfig.QueryDeploymentForTerraformResponse
-	err = resource.RetryContext(context, time.Duration(12)*time.Hour, func() *resource.RetryError {
-		deploymentResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePropertyConfigClient().QueryDeployment(deploymentId)
+// resourceCdnPropertyDeploymentUpdate only handles wait_for_deployment, every other
+// argument forces a new deployment. Turning it on waits for a deployment still in
+// progress.
+func resourceCdnPropertyDeploymentUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("resource.wangsu_cdn_property_deployment.update")
+	if data.HasChange("wait_for_deployment") && data.Get("wait_for_deployment").(bool) {
+		deploymentId, err := strconv.Atoi(data.Id())
 		if err != nil {
-			return resource.NonRetryableError(err)
+			return diag.FromErr(err)
 		}
-		if deploymentResponse != nil && deploymentResponse.Data != nil && *deploymentResponse.Data.Status != "SUCCESS" {
-			return resource.RetryableError(fmt.Errorf("property deployment status is in progress, retrying"))
+		if err = WaitForPropertyDeployment(context, meta, deploymentId, data.Timeout(schema.TimeoutUpdate)); err != nil {
+			return wangsuCommon.DiagnosticsFromError(data, err)
 		}
-		return nil
-	})
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
 	}
-	data.SetId(strconv.Itoa(deploymentId))
-	log.Printf("resource.wangsu_cdn_property_deployment.create success, deploymentId: %d", response.Data.DeploymentId)
 	return resourceCdnPropertyDeploymentRead(context, data, meta)
 }
 
+// WaitForPropertyDeployment polls a property deployment until it succeeds. A
+// failed deployment is reported at once.
+func WaitForPropertyDeployment(ctx context.Context, meta interface{}, deploymentId int, timeout time.Duration) error {
+	waiter := &wangsuCommon.DeployWaiter{
+		Pending:     []string{"PENDING", "IN_PROCESS"},
+		Target:      []string{"SUCCESS"},
+		Failure:     []string{"FAIL"},
+		Delay:       2 * time.Second,
+		MinInterval: 2 * time.Second,
+		MaxInterval: 10 * time.Second,
+		Timeout:     timeout,
+		Description: fmt.Sprintf("property deployment %d", deploymentId),
+		Refresh: func(ctx context.Context) (string, string, error) {
+			conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+			client, err := conn.UsePropertyConfigClient()
+			if err != nil {
+				return "", "", err
+			}
+			var deploymentResponse *propertyConfig.QueryDeploymentForTerraformResponse
+			err = conn.Call(ctx, connectivity.EndpointPropertyConfig, "QueryDeployment", func() (string, error) {
+				deploymentResponse, err = client.QueryDeployment(deploymentId)
+				return "", wangsuCommon.NewAPIError(err, "")
+			})
+			if err != nil {
+				return "", "", err
+			}
+			if deploymentResponse == nil || deploymentResponse.Data == nil || deploymentResponse.Data.Status == nil {
+				return "SUCCESS", "", nil
+			}
+			return *deploymentResponse.Data.Status, "", nil
+		},
+	}
+	_, err := waiter.WaitForStatus(ctx)
+	return err
+}
+
 func resourceCdnPropertyDeploymentDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_cdn_property_deployment.delete do nothing")
 	return nil
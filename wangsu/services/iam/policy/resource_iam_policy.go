@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	policy "github.com/wangsu-api/wangsu-sdk-go/wangsu/policy"
 	"log"
 	"strconv"
@@ -56,14 +57,22 @@ func resourceIamPolicyCreate(context context.Context, data *schema.ResourceData,
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyClient().AddPolicy(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointIam, "AddPolicy", func() (string, error) {
+			requestId, response, err = client.AddPolicy(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -73,7 +82,9 @@ func resourceIamPolicyCreate(context context.Context, data *schema.ResourceData,
 	data.SetId(strconv.FormatInt(*response.Data.PolicyId, 10))
 	log.Printf("resource.wangsu_iam_policy.create success")
 	log.Printf("requestId: %s", requestId)
-	time.Sleep(2 * time.Second)
+	if err := wangsuCommon.SleepContext(context, 2*time.Second); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 	return resourceIamPolicyRead(context, data, meta)
 }
 
@@ -90,7 +101,15 @@ func resourceIamPolicyRead(context context.Context, data *schema.ResourceData, m
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyClient().GetPolicy(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointIam, "GetPolicy", func() (string, error) {
+			requestId, response, err = client.GetPolicy(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -98,12 +117,11 @@ func resourceIamPolicyRead(context context.Context, data *schema.ResourceData, m
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_iam_policy")
 	}
 	_ = data.Set("policy_name", response.Data.PolicyName)
 	_ = data.Set("description", response.Data.Description)
@@ -135,14 +153,22 @@ func resourceIamPolicyUpdate(context context.Context, data *schema.ResourceData,
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyClient().EditPolicy(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointIam, "EditPolicy", func() (string, error) {
+			requestId, response, err = client.EditPolicy(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -151,7 +177,9 @@ func resourceIamPolicyUpdate(context context.Context, data *schema.ResourceData,
 	}
 	log.Printf("resource.wangsu_iam_policy.update success")
 	log.Printf("requestId: %s", requestId)
-	time.Sleep(2 * time.Second)
+	if err := wangsuCommon.SleepContext(context, 2*time.Second); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 	return resourceIamPolicyRead(context, data, meta)
 }
 
@@ -168,14 +196,22 @@ func resourceIamPolicyDelete(context context.Context, data *schema.ResourceData,
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyClient().DeletePolicy(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointIam, "DeletePolicy", func() (string, error) {
+			requestId, response, err = client.DeletePolicy(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"log"
 	"time"
 
@@ -74,14 +75,22 @@ func resourceIamPolicyAttachmentCreate(ctx context.Context, data *schema.Resourc
 	var requestId string
 	var err error
 	err = resource.RetryContext(ctx, time.Duration(5)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = m.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyAttachmentClient().BatchAddOrRevokePolicyToSubAccount(request)
+		conn := m.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyAttachmentClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointIam, "BatchAddOrRevokePolicyToSubAccount", func() (string, error) {
+			requestId, response, err = client.BatchAddOrRevokePolicyToSubAccount(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -117,14 +126,22 @@ func ResourceIamPolicyAttachmentDelete(ctx context.Context, data *schema.Resourc
 	var requestId string
 	var err error
 	err = resource.RetryContext(ctx, time.Duration(5)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = m.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyAttachmentClient().BatchAddOrRevokePolicyToSubAccount(request)
+		conn := m.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyAttachmentClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointIam, "BatchAddOrRevokePolicyToSubAccount", func() (string, error) {
+			requestId, response, err = client.BatchAddOrRevokePolicyToSubAccount(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -144,7 +161,7 @@ func ResourceIamPolicyAttachmentUpdate(ctx context.Context, data *schema.Resourc
 	log.Printf("getPolicyAttachment requestId: %s", requestId1)
 
 	if err1 != nil {
-		diags = append(diags, diag.FromErr(err1)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err1)...)
 		return diags
 	}
 	if response1 == nil || response1.Data == nil {
@@ -195,7 +212,7 @@ func ResourceIamPolicyAttachmentUpdate(ctx context.Context, data *schema.Resourc
 	if len(missingPolicyNames) > 0 {
 		response2, requestId2, err2 := updatePolicyAttachment(ctx, request, missingPolicyNames, 1, m)
 		if err2 != nil {
-			diags = append(diags, diag.FromErr(err2)...)
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err2)...)
 			return diags
 		}
 		if response2 == nil {
@@ -207,7 +224,7 @@ func ResourceIamPolicyAttachmentUpdate(ctx context.Context, data *schema.Resourc
 	if len(extraPolicyNames) > 0 {
 		response3, requestId3, err3 := updatePolicyAttachment(ctx, request, extraPolicyNames, 0, m)
 		if err3 != nil {
-			diags = append(diags, diag.FromErr(err3)...)
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err3)...)
 			return diags
 		}
 		if response3 == nil {
@@ -228,7 +245,15 @@ func updatePolicyAttachment(ctx context.Context, request *policyAttachment.Batch
 	var requestId string
 	var err error
 	err = resource.RetryContext(ctx, time.Duration(5)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = m.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyAttachmentClient().BatchAddOrRevokePolicyToSubAccount(request)
+		conn := m.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyAttachmentClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointIam, "BatchAddOrRevokePolicyToSubAccount", func() (string, error) {
+			requestId, response, err = client.BatchAddOrRevokePolicyToSubAccount(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -242,12 +267,14 @@ func ResourceIamPolicyAttachmentRead(ctx context.Context, data *schema.ResourceD
 	var diags diag.Diagnostics
 	response, requestId, err := getPolicyAttachment(ctx, data, m)
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		if wangsuCommon.IsNotFound(err) {
+			return wangsuCommon.RemoveFromState(data, "wangsu_iam_policy_attachment")
+		}
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_iam_policy_attachment")
 	}
 	dataList := make([]interface{}, 0)
 	for _, item := range response.Data {
@@ -269,7 +296,15 @@ func getPolicyAttachment(ctx context.Context, data *schema.ResourceData, m inter
 	var requestId string
 	var err error
 	err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = m.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UsePolicyAttachmentClient().QueryPolicyAttachedMainAccountOrSubAccount(request)
+		conn := m.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UsePolicyAttachmentClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointIam, "QueryPolicyAttachedMainAccountOrSubAccount", func() (string, error) {
+			requestId, response, err = client.QueryPolicyAttachedMainAccountOrSubAccount(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
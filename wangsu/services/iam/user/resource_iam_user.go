@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/usermanage"
 	"golang.org/x/net/context"
 )
@@ -90,14 +91,22 @@ func resourceUserCreate(ctx context.Context, data *schema.ResourceData, meta int
 	var err error
 	var requestId string
 	err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseUserManageClient().CreateUser(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseUserManageClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointIam, "CreateUser", func() (string, error) {
+			requestId, response, err = client.CreateUser(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -109,7 +118,9 @@ func resourceUserCreate(ctx context.Context, data *schema.ResourceData, meta int
 	_ = data.Set("login_name", *request.LoginName)
 	log.Printf("resource.wangsu_iam_user.create success")
 	log.Printf("requestId: %s", requestId)
-	time.Sleep(2 * time.Second)
+	if err := wangsuCommon.SleepContext(ctx, 2*time.Second); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 	return resourceUserRead(ctx, data, meta)
 }
 
@@ -126,19 +137,29 @@ func resourceUserRead(ctx context.Context, data *schema.ResourceData, meta inter
 		var err error
 		var requestId string
 		err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-			requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseUserManageClient().QueryUser(request, path)
+			conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+			client, clientErr := conn.UseUserManageClient()
+			if clientErr != nil {
+				return resource.NonRetryableError(clientErr)
+			}
+			err = conn.Call(ctx, connectivity.EndpointIam, "QueryUser", func() (string, error) {
+				requestId, response, err = client.QueryUser(request, path)
+				return requestId, wangsuCommon.NewAPIError(err, requestId)
+			})
 			if err != nil {
 				return resource.NonRetryableError(err)
 			}
 			return nil
 		})
 		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+			if wangsuCommon.IsNotFound(err) {
+				return wangsuCommon.RemoveFromState(data, "wangsu_iam_user")
+			}
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 			return diags
 		}
 		if response == nil || response.Data == nil {
-			data.SetId("")
-			return nil
+			return wangsuCommon.RemoveFromState(data, "wangsu_iam_user")
 		}
 		data.SetId(*response.Data.LoginName)
 		_ = data.Set("login_name", response.Data.LoginName)
@@ -183,14 +204,22 @@ func resourceUserUpdate(ctx context.Context, data *schema.ResourceData, meta int
 	var err error
 	var requestId string
 	err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseUserManageClient().EditUser(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseUserManageClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointIam, "EditUser", func() (string, error) {
+			requestId, response, err = client.EditUser(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -200,7 +229,9 @@ func resourceUserUpdate(ctx context.Context, data *schema.ResourceData, meta int
 	data.SetId(*request.LoginName)
 	log.Printf("resource.wangsu_iam_user.update success")
 	log.Printf("requestId: %s", requestId)
-	time.Sleep(2 * time.Second)
+	if err := wangsuCommon.SleepContext(ctx, 2*time.Second); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 	return resourceUserRead(ctx, data, meta)
 }
 
@@ -217,14 +248,22 @@ func resourceUserDelete(ctx context.Context, data *schema.ResourceData, meta int
 		var err error
 		var requestId string
 		err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-			requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseUserManageClient().DeleteUser(request, path)
+			conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+			client, clientErr := conn.UseUserManageClient()
+			if clientErr != nil {
+				return resource.NonRetryableError(clientErr)
+			}
+			err = conn.Call(ctx, connectivity.EndpointIam, "DeleteUser", func() (string, error) {
+				requestId, response, err = client.DeleteUser(request, path)
+				return requestId, wangsuCommon.NewAPIError(err, requestId)
+			})
 			if err != nil {
 				return resource.NonRetryableError(err)
 			}
 			return nil
 		})
 		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 			return diags
 		}
 		if response == nil {
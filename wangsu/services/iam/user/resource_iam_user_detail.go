@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/usermanage"
 	"golang.org/x/net/context"
 )
@@ -77,14 +78,22 @@ func resourceIamUserDetailRead(ctx context.Context, data *schema.ResourceData, m
 		var err error
 		var requestId string
 		err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-			requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseUserManageClient().QueryUser(request, path)
+			conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+			client, clientErr := conn.UseUserManageClient()
+			if clientErr != nil {
+				return resource.NonRetryableError(clientErr)
+			}
+			err = conn.Call(ctx, connectivity.EndpointIam, "QueryUser", func() (string, error) {
+				requestId, response, err = client.QueryUser(request, path)
+				return requestId, wangsuCommon.NewAPIError(err, requestId)
+			})
 			if err != nil {
 				return resource.NonRetryableError(err)
 			}
 			return nil
 		})
 		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 			return diags
 		}
 		if response == nil || response.Data == nil {
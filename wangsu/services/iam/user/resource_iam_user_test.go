@@ -0,0 +1,51 @@
+package user_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccIamUser_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			if _, ok := server.Collection("iam_user").Get("tf-acc-test-user"); ok {
+				return fmt.Errorf("sub-account tf-acc-test-user left after destroy")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccIamUserConfig("tf-acc-test user"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wangsu_iam_user.test", "id", "tf-acc-test-user"),
+					resource.TestCheckResourceAttr("wangsu_iam_user.test", "display_name", "tf-acc-test user"),
+				),
+			},
+			{
+				Config: server.ProviderConfig() + testAccIamUserConfig("tf-acc-test renamed"),
+				Check:  resource.TestCheckResourceAttr("wangsu_iam_user.test", "display_name", "tf-acc-test renamed"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccIamUserConfig(displayName string) string {
+	return fmt.Sprintf(`
+resource "wangsu_iam_user" "test" {
+  login_name   = "tf-acc-test-user"
+  display_name = %q
+  email        = "tf-acc-test@example.com"
+}
+`, displayName)
+}
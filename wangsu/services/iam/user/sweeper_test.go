@@ -0,0 +1,64 @@
+package user
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/sweep"
+)
+
+const sweepPageSize = 100
+
+func init() {
+	resource.AddTestSweepers("wangsu_iam_user", &resource.Sweeper{
+		Name: "wangsu_iam_user",
+		F:    sweepIamUsers,
+	})
+}
+
+// TestMain runs the sweepers when the tests are started with -sweep, e.g.
+// go test ./wangsu/services/... -sweep=all.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func sweepIamUsers(region string) error {
+	meta, err := sweep.SharedMeta(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	// list every page before deleting anything, deletions would shift the pages
+	var loginNames []string
+	for page := 1; ; page++ {
+		data, err := sweep.Read(ctx, ResourceIamUsers(), map[string]interface{}{
+			"page_size":   sweepPageSize,
+			"page_number": page,
+		}, meta)
+		if err != nil {
+			return err
+		}
+		users := data.Get("data").([]interface{})
+		for _, item := range users {
+			user := item.(map[string]interface{})
+			if sweep.HasPrefix(user["login_name"], user["display_name"]) {
+				loginNames = append(loginNames, user["login_name"].(string))
+			}
+		}
+		if len(users) < sweepPageSize {
+			break
+		}
+	}
+
+	var errs sweep.Errors
+	for _, loginName := range loginNames {
+		log.Printf("[INFO] Deleting IAM sub-account %s", loginName)
+		errs.Add("IAM sub-account", loginName, sweep.Delete(ctx, ResourceUserInfo(), loginName, map[string]interface{}{
+			"login_name": loginName,
+		}, meta))
+	}
+	return errs.Err()
+}
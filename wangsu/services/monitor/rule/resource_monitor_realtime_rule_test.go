@@ -0,0 +1,74 @@
+package rule_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccMonitorRealtimeRule_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			if n := server.Collection("monitor_realtime_rule").Len(); n > 0 {
+				return fmt.Errorf("%d monitor rules left after destroy", n)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccMonitorRealtimeRuleConfig("100000"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("wangsu_monitor_realtime_rule.test", "rule_id"),
+					resource.TestCheckResourceAttr("wangsu_monitor_realtime_rule.test", "rule_items.0.condition_rules.0.threshold", "100000"),
+				),
+			},
+			{
+				Config: server.ProviderConfig() + testAccMonitorRealtimeRuleConfig("200000"),
+				Check:  resource.TestCheckResourceAttr("wangsu_monitor_realtime_rule.test", "rule_items.0.condition_rules.0.threshold", "200000"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccMonitorRealtimeRuleConfig(threshold string) string {
+	return fmt.Sprintf(`
+resource "wangsu_monitor_realtime_rule" "test" {
+  rule_name          = "tf_acc_test"
+  monitor_product    = "web"
+  resource_type      = "domain"
+  monitor_resources  = ["ALL"]
+  statistical_method = "CONSOLIDATED"
+  alert_frequency    = 5
+  restore_notice     = "false"
+
+  rule_items {
+    start_time     = "00:00"
+    end_time       = "23:59"
+    condition_type = "ANY"
+    period         = 5
+    period_times   = 1
+
+    condition_rules {
+      monitor_item = "BANDWIDTH"
+      condition    = "MAX"
+      threshold    = %q
+    }
+  }
+
+  notices {
+    notice_method = "WEBHOOK"
+    notice_object = "https://tf-acc-test.example.com/alerts"
+  }
+}
+`, threshold)
+}
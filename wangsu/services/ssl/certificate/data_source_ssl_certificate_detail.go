@@ -5,6 +5,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	certicate "github.com/wangsu-api/wangsu-sdk-go/wangsu/ssl/certificate"
 	"golang.org/x/net/context"
 	"log"
@@ -91,7 +92,15 @@ func dataSourceSslCertificateDetailRead(context context.Context, data *schema.Re
 	var diags diag.Diagnostics
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateClient().QueryCertificate(int64(certificateId))
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointSsl, "QueryCertificate", func() (string, error) {
+			requestId, response, err = client.QueryCertificate(int64(certificateId))
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -99,7 +108,7 @@ func dataSourceSslCertificateDetailRead(context context.Context, data *schema.Re
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	certicate "github.com/wangsu-api/wangsu-sdk-go/wangsu/ssl/certificate"
 	"log"
 	"strconv"
@@ -67,14 +68,22 @@ func resourceSslCertificateCreate(context context.Context, data *schema.Resource
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateClient().AddCertificate(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointSsl, "AddCertificate", func() (string, error) {
+			requestId, response, err = client.AddCertificate(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -86,7 +95,9 @@ func resourceSslCertificateCreate(context context.Context, data *schema.Resource
 	data.SetId(certificateIdStr)
 	log.Printf("resource.wangsu_ssl_certificate.create success")
 	log.Printf("requestId: %s", requestId)
-	time.Sleep(2 * time.Second)
+	if err := wangsuCommon.SleepContext(context, 2*time.Second); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 	return resourceSslCertificateRead(context, data, meta)
 }
 
@@ -98,19 +109,29 @@ func resourceSslCertificateRead(context context.Context, data *schema.ResourceDa
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateClient().QueryCertificate(certificateId)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointSsl, "QueryCertificate", func() (string, error) {
+			requestId, response, err = client.QueryCertificate(certificateId)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		if wangsuCommon.IsNotFound(err) {
+			return wangsuCommon.RemoveFromState(data, "wangsu_ssl_certificate")
+		}
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_ssl_certificate")
 	}
 	data.Set("certificate_id", response.Data.CertificateId)
 	data.Set("name", response.Data.Name)
@@ -140,14 +161,22 @@ func resourceSslCertificateUpdate(context context.Context, data *schema.Resource
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateClient().UpdateCertificate(certificateId, request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointSsl, "UpdateCertificate", func() (string, error) {
+			requestId, response, err = client.UpdateCertificate(certificateId, request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -167,14 +196,22 @@ func resourceSslCertificateDelete(context context.Context, data *schema.Resource
 	var requestId string
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		requestId, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateClient().DeleteCertificate(certificateId)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointSsl, "DeleteCertificate", func() (string, error) {
+			requestId, response, err = client.DeleteCertificate(certificateId)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
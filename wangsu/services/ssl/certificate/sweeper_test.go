@@ -0,0 +1,88 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	cdnDomain "github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/cdn/domain"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/sweep"
+)
+
+func init() {
+	resource.AddTestSweepers("wangsu_ssl_certificate", &resource.Sweeper{
+		Name: "wangsu_ssl_certificate",
+		F:    sweepSslCertificates,
+	})
+}
+
+// TestMain runs the sweepers when the tests are started with -sweep, e.g.
+// go test ./wangsu/services/... -sweep=all.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func sweepSslCertificates(region string) error {
+	meta, err := sweep.SharedMeta(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	data, err := sweep.Read(ctx, DataSourceSslCertificates(), nil, meta)
+	if err != nil {
+		return err
+	}
+
+	var errs sweep.Errors
+	for _, item := range data.Get("data").([]interface{}) {
+		certificate := item.(map[string]interface{})
+		if !sweep.HasPrefix(certificate["name"], certificate["comment"]) {
+			continue
+		}
+		certificateId := strconv.Itoa(certificate["certificate_id"].(int))
+		// a certificate cannot be deleted while a domain uses it
+		if err := sweepRelatedDomains(ctx, certificateId, certificate["related_domains"].([]interface{}), meta); err != nil {
+			if errors.Is(err, errDomainInUse) {
+				log.Printf("[WARN] Skipping SSL certificate %s: %s", certificateId, err)
+			} else {
+				errs.Add("SSL certificate", certificateId, err)
+			}
+			continue
+		}
+		log.Printf("[INFO] Deleting SSL certificate %s (%s)", certificateId, certificate["name"])
+		errs.Add("SSL certificate", certificateId, sweep.Delete(ctx, ResourceSslCertificate(), certificateId, nil, meta))
+	}
+	return errs.Err()
+}
+
+var errDomainInUse = errors.New("it is used by a domain that is not a test domain")
+
+// sweepRelatedDomains deletes the domains using a certificate, so that it can be
+// deleted next. They are test domains too, so this does not depend on the domain
+// sweepers having run first; when another domain uses the certificate, nothing
+// is deleted and errDomainInUse is returned.
+func sweepRelatedDomains(ctx context.Context, certificateId string, related []interface{}, meta interface{}) error {
+	domainNames := make([]string, 0, len(related))
+	for _, item := range related {
+		domainName, _ := item.(map[string]interface{})["domain_name"].(string)
+		if !sweep.HasPrefix(domainName) {
+			return fmt.Errorf("%w: %s", errDomainInUse, domainName)
+		}
+		domainNames = append(domainNames, domainName)
+	}
+	for _, domainName := range domainNames {
+		log.Printf("[INFO] Deleting domain %s, it uses SSL certificate %s", domainName, certificateId)
+		// the CDN delete API deletes APPA domains too
+		err := sweep.Delete(ctx, cdnDomain.ResourceCdnDomain(), domainName, map[string]interface{}{
+			"domain_name": domainName,
+		}, meta)
+		if err != nil {
+			return fmt.Errorf("domain %s: %w", domainName, err)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/certificateapplication"
 	"log"
 	"time"
@@ -202,7 +203,16 @@ func dataSourceSslCertificateApplicationDetailRead(ctx context.Context, d *schem
 	// SDK 查询，重试机制
 	err = resource.RetryContext(ctx, time.Minute*2, func() *resource.RetryError {
 		// 请根据实际 ProviderMeta 和 SDK 客户端替换此调用
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateApplicationClient().GetCertificateApplicationDetail(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateApplicationClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointCertificateApplication, "GetCertificateApplicationDetail", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetCertificateApplicationDetail(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -210,7 +220,7 @@ func dataSourceSslCertificateApplicationDetailRead(ctx context.Context, d *schem
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(d, err)...)
 		return diags
 	}
 
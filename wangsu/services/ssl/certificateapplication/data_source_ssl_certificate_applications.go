@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/certificateapplication"
 )
 
@@ -206,7 +207,16 @@ func dataSourceSslCertificateApplicationsRead(ctx context.Context, d *schema.Res
 
 	// 使用 SDK 调用 API
 	err = resource.RetryContext(ctx, time.Minute*2, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateApplicationClient().ListCertificateApplication(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateApplicationClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointCertificateApplication, "ListCertificateApplication", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.ListCertificateApplication(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -214,7 +224,7 @@ func dataSourceSslCertificateApplicationsRead(ctx context.Context, d *schema.Res
 	})
 
 	if err != nil {
-		return diag.FromErr(err)
+		return wangsuCommon.DiagnosticsFromError(d, err)
 	}
 
 	if response == nil || response.Data == nil {
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/wangsu-sdk-go/wangsu/certificateapplication"
 	"log"
 	"time"
@@ -190,7 +191,11 @@ func resourceSslCertificateApplicationDelete(context context.Context, data *sche
 		return nil
 	}
 
-	client := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateApplicationClient()
+	conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+	client, err := conn.UseSslCertificateApplicationClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	orderId := data.Id()
 	if orderId == "" {
 		data.SetId("")
@@ -200,10 +205,12 @@ func resourceSslCertificateApplicationDelete(context context.Context, data *sche
 	request := &certificateapplication.CancelCertificateApplicationOrderForTerraformRequest{
 		OrderId: &orderId,
 	}
-	var err error
-	_, _, err = client.CancelCertificateApplication(request)
+	err = conn.Call(context, connectivity.EndpointCertificateApplication, "CancelCertificateApplication", func() (string, error) {
+		requestId, _, err := client.CancelCertificateApplication(request)
+		return requestId, wangsuCommon.NewAPIError(err, requestId)
+	})
 	if err != nil {
-		return diag.FromErr(err)
+		return wangsuCommon.DiagnosticsFromError(data, err)
 	}
 	data.SetId("")
 	return nil
@@ -293,14 +300,23 @@ func resourceSslCertificateApplicationCreate(context context.Context, data *sche
 	var response *certificateapplication.CreateCertificateApplicationOrderForTerraformResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateApplicationClient().CreateCertificateApplication(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateApplicationClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCertificateApplication, "CreateCertificateApplication", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.CreateCertificateApplication(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -387,19 +403,27 @@ func getCertificateApplicationDetail(context context.Context, data *schema.Resou
 
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSslCertificateApplicationClient().GetCertificateApplicationDetail(request)
+		conn := meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn()
+		client, clientErr := conn.UseSslCertificateApplicationClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointCertificateApplication, "GetCertificateApplicationDetail", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetCertificateApplicationDetail(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return nil, diags, true
 	}
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil, nil, true
+		return nil, wangsuCommon.RemoveFromState(data, "wangsu_ssl_certificate_application"), true
 	}
 	return response, nil, false
 }
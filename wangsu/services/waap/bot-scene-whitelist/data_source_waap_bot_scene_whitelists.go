@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapBotSceneWhitelist "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/bot-scene-whitelist"
 	"log"
 	"time"
@@ -16,6 +17,7 @@ func DataSourceBotSceneWhitelist() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceBotSceneWhitelistRead,
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain_list": {
 				Type:        schema.TypeList,
 				Required:    true,
@@ -104,7 +106,16 @@ func dataSourceBotSceneWhitelistRead(context context.Context, data *schema.Resou
 		request.SetDomainList(targetDomainsStr)
 	}
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotSceneWhiteListClient().GetList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotSceneWhiteListClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -112,7 +123,7 @@ func dataSourceBotSceneWhitelistRead(context context.Context, data *schema.Resou
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
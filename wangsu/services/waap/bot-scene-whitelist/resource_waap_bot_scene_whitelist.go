@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapBotSceneWhitelist "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/bot-scene-whitelist"
 	"log"
 	"time"
@@ -20,6 +21,7 @@ func ResourceWaapBotSceneWhitelist() *schema.Resource {
 		DeleteContext: resourceWaapBotSceneWhitelistDelete,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -78,6 +80,11 @@ func ResourceWaapBotSceneWhitelist() *schema.Resource {
 
 func resourceWaapBotSceneWhitelistCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_bot_scene_whitelist.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &waapBotSceneWhitelist.AddSpecificClientTrafficBypassRequest{}
@@ -124,7 +131,16 @@ func resourceWaapBotSceneWhitelistCreate(context context.Context, data *schema.R
 	var response *waapBotSceneWhitelist.AddSpecificClientTrafficBypassResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotSceneWhiteListClient().Add(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotSceneWhiteListClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "Add", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Add(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -132,7 +148,7 @@ func resourceWaapBotSceneWhitelistCreate(context context.Context, data *schema.R
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -154,56 +170,65 @@ func resourceWaapBotSceneWhitelistRead(context context.Context, data *schema.Res
 		request := &waapBotSceneWhitelist.ListSpecificClientTrafficBypassRequest{
 			DomainList: []*string{&domain},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotSceneWhiteListClient().GetList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotSceneWhiteListClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_bot_scene_whitelist")
 	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
-			}
-			_ = data.Set("domain", item.Domain)
-			_ = data.Set("name", item.Name)
-			_ = data.Set("description", item.Description)
-			// 映射 conditions 数据
-			conditions := make([]map[string]interface{}, len(item.Conditions))
-			for i, cond := range item.Conditions {
-				conditions[i] = map[string]interface{}{
-					"match_name": *cond.MatchName,
-					"match_type": *cond.MatchType,
-					"match_key": func() string {
-						if cond.MatchKey != nil {
-							return *cond.MatchKey
-						}
-						return ""
-					}(),
-					"match_value_list": cond.MatchValueList,
+	item := response.Data[index]
+	_ = data.Set("domain", item.Domain)
+	_ = data.Set("name", item.Name)
+	_ = data.Set("description", item.Description)
+	// 映射 conditions 数据
+	conditions := make([]map[string]interface{}, len(item.Conditions))
+	for i, cond := range item.Conditions {
+		conditions[i] = map[string]interface{}{
+			"match_name": *cond.MatchName,
+			"match_type": *cond.MatchType,
+			"match_key": func() string {
+				if cond.MatchKey != nil {
+					return *cond.MatchKey
 				}
-			}
-			_ = data.Set("conditions", conditions)
+				return ""
+			}(),
+			"match_value_list": cond.MatchValueList,
 		}
 	}
+	_ = data.Set("conditions", conditions)
 	return nil
 }
 
 func resourceWaapBotSceneWhitelistUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_bot_scene_whitelist.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	if data.HasChange("domain") {
@@ -261,14 +286,23 @@ func resourceWaapBotSceneWhitelistUpdate(context context.Context, data *schema.R
 	var response *waapBotSceneWhitelist.UpdateSpecificClientTrafficBypassResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotSceneWhiteListClient().Update(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotSceneWhiteListClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "Update", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Update(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -279,6 +313,11 @@ func resourceWaapBotSceneWhitelistUpdate(context context.Context, data *schema.R
 
 func resourceWaapBotSceneWhitelistDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_bot_scene_whitelist.delete")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *waapBotSceneWhitelist.DeleteSpecificClientTrafficBypassResponse
 	var err error
@@ -288,14 +327,23 @@ func resourceWaapBotSceneWhitelistDelete(context context.Context, data *schema.R
 		request := &waapBotSceneWhitelist.DeleteSpecificClientTrafficBypassRequest{
 			IdList: []*string{&id},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotSceneWhiteListClient().Delete(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotSceneWhiteListClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "Delete", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Delete(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapBot "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/bot"
 	"log"
 	"time"
@@ -17,6 +18,7 @@ func DataSourceWaapBot() *schema.Resource {
 		ReadContext: dataSourceWaapBotRead,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain_list": {
 				Type:        schema.TypeList,
 				Required:    true,
@@ -409,7 +411,16 @@ func dataSourceWaapBotRead(context context.Context, data *schema.ResourceData, m
 	var response *waapBot.GetBotManagementConfigResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotClient().GetBotManagementConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetBotManagementConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetBotManagementConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -417,7 +428,7 @@ func dataSourceWaapBotRead(context context.Context, data *schema.ResourceData, m
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
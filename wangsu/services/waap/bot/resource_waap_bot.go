@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapBot "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/bot"
 	"log"
 	"time"
@@ -25,6 +26,7 @@ func ResourceWaapBot() *schema.Resource {
 		},
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -220,7 +222,16 @@ func ResourceWaapBotRead(context context.Context, data *schema.ResourceData, met
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
 		domain := data.Id()
 		request.SetDomainList([]*string{&domain})
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotClient().GetBotManagementConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetBotManagementConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetBotManagementConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -228,12 +239,11 @@ func ResourceWaapBotRead(context context.Context, data *schema.ResourceData, met
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_bot_config")
 	}
 
 	// Read response data
@@ -244,6 +254,11 @@ func ResourceWaapBotRead(context context.Context, data *schema.ResourceData, met
 
 func ResourceWaapBotUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_bot.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 	var diags diag.Diagnostics
 
 	if data.HasChange("domain") {
@@ -260,7 +275,16 @@ func ResourceWaapBotUpdate(context context.Context, data *schema.ResourceData, m
 	var response *waapBot.UpdateBotManagementConfigResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapBotClient().UpdateBotManagementConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateBotManagementConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateBotManagementConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -268,7 +292,7 @@ func ResourceWaapBotUpdate(context context.Context, data *schema.ResourceData, m
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || tea.StringValue(response.Code) != "200" {
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapCustomizerule "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/customizerule"
 	"log"
 	"time"
@@ -16,6 +17,7 @@ func DataSourceCustomizeRule() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceCustomizeRuleRead,
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain_list": {
 				Type:        schema.TypeList,
 				Required:    true,
@@ -283,7 +285,16 @@ func dataSourceCustomizeRuleRead(context context.Context, data *schema.ResourceD
 		request.SetDomainList(targetDomainsStr)
 	}
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapCustomizeruleClient().GetCustomRuleList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetCustomRuleList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetCustomRuleList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -291,7 +302,7 @@ func dataSourceCustomizeRuleRead(context context.Context, data *schema.ResourceD
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapCustomizerule "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/customizerule"
 	"log"
 	"time"
@@ -20,6 +21,7 @@ func ResourceWaapCustomizeRule() *schema.Resource {
 		DeleteContext: resourceWaapCustomizeRuleDelete,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -318,6 +320,11 @@ func ResourceWaapCustomizeRule() *schema.Resource {
 
 func resourceWaapCustomizeRuleCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_customize_rule.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &waapCustomizerule.AddCustomizeRuleRequest{}
@@ -583,7 +590,16 @@ func resourceWaapCustomizeRuleCreate(context context.Context, data *schema.Resou
 	var response *waapCustomizerule.AddCustomizeRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapCustomizeruleClient().AddCustomRule(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "AddCustomRule", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.AddCustomRule(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -591,7 +607,7 @@ func resourceWaapCustomizeRuleCreate(context context.Context, data *schema.Resou
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -618,7 +634,16 @@ func resourceWaapCustomizeRuleRead(context context.Context, data *schema.Resourc
 			DomainList: []*string{&domain},
 			//RuleName:   &ruleName,
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapCustomizeruleClient().GetCustomRuleList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetCustomRuleList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetCustomRuleList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -626,163 +651,163 @@ func resourceWaapCustomizeRuleRead(context context.Context, data *schema.Resourc
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_customizerule")
 	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
-			}
-			_ = data.Set("domain", item.Domain)
-			_ = data.Set("rule_name", item.RuleName)
-			_ = data.Set("description", item.Description)
-			_ = data.Set("scene", item.Scene)
-			_ = data.Set("api_id", item.ApiId)
-			_ = data.Set("act", item.Act)
-			condition := make(map[string]interface{})
-			if item.ConditionList != nil {
-				if item.ConditionList.IpOrIpsConditions != nil {
-					ipOrIpsConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.IpOrIpsConditions {
-						ipOrIpsCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ip_or_ips":  condition.IpOrIps,
-						}
-						ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
-					}
-					condition["ip_or_ips_conditions"] = ipOrIpsConditions
+	item := response.Data[index]
+	_ = data.Set("domain", item.Domain)
+	_ = data.Set("rule_name", item.RuleName)
+	_ = data.Set("description", item.Description)
+	_ = data.Set("scene", item.Scene)
+	_ = data.Set("api_id", item.ApiId)
+	_ = data.Set("act", item.Act)
+	condition := make(map[string]interface{})
+	if item.ConditionList != nil {
+		if item.ConditionList.IpOrIpsConditions != nil {
+			ipOrIpsConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.IpOrIpsConditions {
+				ipOrIpsCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ip_or_ips":  condition.IpOrIps,
 				}
-				if item.ConditionList.PathConditions != nil {
-					pathConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.PathConditions {
-						pathCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"paths":      condition.Paths,
-						}
-						pathConditions = append(pathConditions, pathCondition)
-					}
-					condition["path_conditions"] = pathConditions
+				ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
+			}
+			condition["ip_or_ips_conditions"] = ipOrIpsConditions
+		}
+		if item.ConditionList.PathConditions != nil {
+			pathConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.PathConditions {
+				pathCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"paths":      condition.Paths,
 				}
-				if item.ConditionList.UriConditions != nil {
-					uriConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.UriConditions {
-						uriCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"uri":        condition.Uri,
-						}
-						uriConditions = append(uriConditions, uriCondition)
-					}
-					condition["uri_conditions"] = uriConditions
+				pathConditions = append(pathConditions, pathCondition)
+			}
+			condition["path_conditions"] = pathConditions
+		}
+		if item.ConditionList.UriConditions != nil {
+			uriConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.UriConditions {
+				uriCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"uri":        condition.Uri,
 				}
-				if item.ConditionList.UriParamConditions != nil {
-					uriParamConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.UriParamConditions {
-						uriParamCondition := map[string]interface{}{
-							"match_type":  condition.MatchType,
-							"param_name":  condition.ParamName,
-							"param_value": condition.ParamValue,
-						}
-						uriParamConditions = append(uriParamConditions, uriParamCondition)
-					}
-					condition["uri_param_conditions"] = uriParamConditions
+				uriConditions = append(uriConditions, uriCondition)
+			}
+			condition["uri_conditions"] = uriConditions
+		}
+		if item.ConditionList.UriParamConditions != nil {
+			uriParamConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.UriParamConditions {
+				uriParamCondition := map[string]interface{}{
+					"match_type":  condition.MatchType,
+					"param_name":  condition.ParamName,
+					"param_value": condition.ParamValue,
 				}
-				if item.ConditionList.UaConditions != nil {
-					uaConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.UaConditions {
-						uaCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ua":         condition.Ua,
-						}
-						uaConditions = append(uaConditions, uaCondition)
-					}
-					condition["ua_conditions"] = uaConditions
+				uriParamConditions = append(uriParamConditions, uriParamCondition)
+			}
+			condition["uri_param_conditions"] = uriParamConditions
+		}
+		if item.ConditionList.UaConditions != nil {
+			uaConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.UaConditions {
+				uaCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ua":         condition.Ua,
 				}
-				if item.ConditionList.RefererConditions != nil {
-					refererConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.RefererConditions {
-						refererCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"referer":    condition.Referer,
-						}
-						refererConditions = append(refererConditions, refererCondition)
-					}
-					condition["referer_conditions"] = refererConditions
+				uaConditions = append(uaConditions, uaCondition)
+			}
+			condition["ua_conditions"] = uaConditions
+		}
+		if item.ConditionList.RefererConditions != nil {
+			refererConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.RefererConditions {
+				refererCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"referer":    condition.Referer,
 				}
-				if item.ConditionList.HeaderConditions != nil {
-					headerConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.HeaderConditions {
-						headerCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"key":        condition.Key,
-							"value_list": condition.ValueList,
-						}
-						headerConditions = append(headerConditions, headerCondition)
-					}
-					condition["header_conditions"] = headerConditions
+				refererConditions = append(refererConditions, refererCondition)
+			}
+			condition["referer_conditions"] = refererConditions
+		}
+		if item.ConditionList.HeaderConditions != nil {
+			headerConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.HeaderConditions {
+				headerCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"key":        condition.Key,
+					"value_list": condition.ValueList,
 				}
-				if item.ConditionList.AreaConditions != nil {
-					areaConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.AreaConditions {
-						areaCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"areas":      condition.Areas,
-						}
-						areaConditions = append(areaConditions, areaCondition)
-					}
-					condition["area_conditions"] = areaConditions
+				headerConditions = append(headerConditions, headerCondition)
+			}
+			condition["header_conditions"] = headerConditions
+		}
+		if item.ConditionList.AreaConditions != nil {
+			areaConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.AreaConditions {
+				areaCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"areas":      condition.Areas,
 				}
-				if item.ConditionList.MethodConditions != nil {
-					methodConditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.MethodConditions {
-						methodCondition := map[string]interface{}{
-							"match_type":     condition.MatchType,
-							"request_method": condition.RequestMethod,
-						}
-						methodConditions = append(methodConditions, methodCondition)
-					}
-					condition["method_conditions"] = methodConditions
+				areaConditions = append(areaConditions, areaCondition)
+			}
+			condition["area_conditions"] = areaConditions
+		}
+		if item.ConditionList.MethodConditions != nil {
+			methodConditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.MethodConditions {
+				methodCondition := map[string]interface{}{
+					"match_type":     condition.MatchType,
+					"request_method": condition.RequestMethod,
 				}
-				if item.ConditionList.Ja3Conditions != nil {
-					ja3Conditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.Ja3Conditions {
-						ja3Condition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ja3_list":   condition.Ja3List,
-						}
-						ja3Conditions = append(ja3Conditions, ja3Condition)
-					}
-					condition["ja3_conditions"] = ja3Conditions
+				methodConditions = append(methodConditions, methodCondition)
+			}
+			condition["method_conditions"] = methodConditions
+		}
+		if item.ConditionList.Ja3Conditions != nil {
+			ja3Conditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.Ja3Conditions {
+				ja3Condition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ja3_list":   condition.Ja3List,
 				}
-				if item.ConditionList.Ja4Conditions != nil {
-					ja4Conditions := make([]interface{}, 0)
-					for _, condition := range item.ConditionList.Ja4Conditions {
-						ja4Condition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ja4_list":   condition.Ja4List,
-						}
-						ja4Conditions = append(ja4Conditions, ja4Condition)
-					}
-					condition["ja4_conditions"] = ja4Conditions
+				ja3Conditions = append(ja3Conditions, ja3Condition)
+			}
+			condition["ja3_conditions"] = ja3Conditions
+		}
+		if item.ConditionList.Ja4Conditions != nil {
+			ja4Conditions := make([]interface{}, 0)
+			for _, condition := range item.ConditionList.Ja4Conditions {
+				ja4Condition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ja4_list":   condition.Ja4List,
 				}
+				ja4Conditions = append(ja4Conditions, ja4Condition)
 			}
-			_ = data.Set("condition", condition)
+			condition["ja4_conditions"] = ja4Conditions
 		}
 	}
+	_ = data.Set("condition", condition)
 	return nil
 }
 
 func resourceWaapCustomizeRuleUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_customize_rule.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 	var diags diag.Diagnostics
 	if data.HasChange("domain") {
 		// 把domain强制刷回旧值，否则会有权限问题
@@ -1056,14 +1081,23 @@ func resourceWaapCustomizeRuleUpdate(context context.Context, data *schema.Resou
 	var response *waapCustomizerule.UpdateCustomRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapCustomizeruleClient().UpdateCustomRule(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateCustomRule", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateCustomRule(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -1075,6 +1109,11 @@ func resourceWaapCustomizeRuleUpdate(context context.Context, data *schema.Resou
 
 func resourceWaapCustomizeRuleDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_customize_rule.delete")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *waapCustomizerule.DeleteCustomRuleResponse
 	var err error
@@ -1084,14 +1123,23 @@ func resourceWaapCustomizeRuleDelete(context context.Context, data *schema.Resou
 		request := &waapCustomizerule.DeleteCustomRuleRequest{
 			IdList: []*string{&id},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapCustomizeruleClient().DeleteCustomRule(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "DeleteCustomRule", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.DeleteCustomRule(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -0,0 +1,60 @@
+package customizerule_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccWaapCustomizeRule_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			if n := server.Collection("waap_customizerule").Len(); n > 0 {
+				return fmt.Errorf("%d custom rules left after destroy", n)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccWaapCustomizeRuleConfig("BLOCK"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("wangsu_waap_customizerule.test", "id"),
+					resource.TestCheckResourceAttr("wangsu_waap_customizerule.test", "act", "BLOCK"),
+					resource.TestCheckResourceAttr("wangsu_waap_customizerule.test", "condition.0.method_conditions.0.request_method.#", "2"),
+				),
+			},
+			{
+				Config: server.ProviderConfig() + testAccWaapCustomizeRuleConfig("LOG"),
+				Check:  resource.TestCheckResourceAttr("wangsu_waap_customizerule.test", "act", "LOG"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccWaapCustomizeRuleConfig(act string) string {
+	return fmt.Sprintf(`
+resource "wangsu_waap_customizerule" "test" {
+  domain    = "tf-acc-test.example.com"
+  rule_name = "tf_acc_test"
+  scene     = "WEB"
+  act       = %q
+
+  condition {
+    method_conditions {
+      match_type     = "EQUAL"
+      request_method = ["PUT", "DELETE"]
+    }
+  }
+}
+`, act)
+}
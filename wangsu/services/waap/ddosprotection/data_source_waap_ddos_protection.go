@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapDDoSProtection "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/ddosprotection"
 	"log"
 	"time"
@@ -17,6 +18,7 @@ func DataSourceWaapDDoSProtection() *schema.Resource {
 		ReadContext: dataSourceWaapDDoSProtectionRead,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain_list": {
 				Type:        schema.TypeList,
 				Required:    true,
@@ -148,7 +150,16 @@ func dataSourceWaapDDoSProtectionRead(context context.Context, data *schema.Reso
 	var response *waapDDoSProtection.GetDDoSProtectionConfigurationResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDDoSProtectionClient().GetDDoSProtectionConfiguration(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapDDoSProtectionClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetDDoSProtectionConfiguration", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetDDoSProtectionConfiguration(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -156,7 +167,7 @@ func dataSourceWaapDDoSProtectionRead(context context.Context, data *schema.Reso
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
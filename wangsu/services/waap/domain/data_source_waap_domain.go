@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapDomain "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/domain"
 	"log"
 	"time"
@@ -17,6 +18,7 @@ func DataSourceWaapDomain() *schema.Resource {
 		ReadContext: dataSourceWaapDomainRead,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"defend_status": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -204,7 +206,16 @@ func dataSourceWaapDomainRead(context context.Context, data *schema.ResourceData
 	}
 
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().GetDomainList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetDomainList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetDomainList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -212,7 +223,7 @@ func dataSourceWaapDomainRead(context context.Context, data *schema.ResourceData
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
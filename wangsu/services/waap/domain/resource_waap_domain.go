@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap"
 	waapDomain "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/domain"
 	"log"
@@ -21,8 +22,15 @@ func ResourceWaapDomain() *schema.Resource {
 		ReadContext:   resourceWaapDomainRead,
 		UpdateContext: resourceWaapDomainUpdate,
 		DeleteContext: resourceWaapDomainDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"waf_defend_config": {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -238,6 +246,11 @@ func ResourceWaapDomain() *schema.Resource {
 
 func resourceWaapDomainCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_domain.create")
+	unlock, lockErr := wangsuCommon.LockDomains(context, data.Get("target_domains").([]interface{}))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &waapDomain.AccessDomainRequest{}
@@ -290,8 +303,17 @@ func resourceWaapDomainCreate(context context.Context, data *schema.ResourceData
 
 	var response *waapDomain.AccessDomainResponse
 	var err error
-	err = resource.RetryContext(context, time.Duration(5)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().AddDomain(request)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "AddDomain", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.AddDomain(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -299,7 +321,7 @@ func resourceWaapDomainCreate(context context.Context, data *schema.ResourceData
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -332,8 +354,17 @@ func resourceWaapDomainRead(context context.Context, data *schema.ResourceData,
 		}
 		request.DomainList = targetDomainsStr
 	}
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().GetDomainList(request)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutRead), func() *resource.RetryError {
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetDomainList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetDomainList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -341,7 +372,7 @@ func resourceWaapDomainRead(context context.Context, data *schema.ResourceData,
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -349,8 +380,7 @@ func resourceWaapDomainRead(context context.Context, data *schema.ResourceData,
 		return nil
 	}
 	if len(response.Data) == 0 || nil == response.Data {
-		data.SetId("")
-		return nil
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_domain")
 	}
 	var item = response.Data[0]
 	// 获取当前的 waf_defend_config 值
@@ -434,6 +464,11 @@ func resourceWaapDomainRead(context context.Context, data *schema.ResourceData,
 
 func resourceWaapDomainUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_domain.update")
+	unlock, lockErr := wangsuCommon.LockDomains(context, data.Get("target_domains").([]interface{}))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	if data.HasChange("target_domains") {
@@ -570,8 +605,17 @@ func resourceWaapDomainUpdate(context context.Context, data *schema.ResourceData
 
 	var response *waapDomain.ModifyPolicyStatusResponse
 	var err error
-	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().UpdateDomainPolicy(request)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateDomainPolicy", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateDomainPolicy(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -579,7 +623,7 @@ func resourceWaapDomainUpdate(context context.Context, data *schema.ResourceData
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -591,6 +635,11 @@ func resourceWaapDomainUpdate(context context.Context, data *schema.ResourceData
 
 func resourceWaapDomainDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_domain.delete")
+	unlock, lockErr := wangsuCommon.LockDomains(context, data.Get("target_domains").([]interface{}))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *waapDomain.RemoveProtectedHostnameResponse
 	var err error
@@ -600,18 +649,27 @@ func resourceWaapDomainDelete(context context.Context, data *schema.ResourceData
 		targetDomainsList := targetDomains.([]interface{})
 		for _, v := range targetDomainsList {
 			domain := v.(string)
-			err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
+			err = resource.RetryContext(context, data.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
 				request := &waapDomain.RemoveProtectedHostnameParameters{
 					Domain: &domain,
 				}
-				_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().DeleteDomain(request)
+				conn := wangsuCommon.APIConn(data, meta)
+				client, clientErr := conn.UseWaapDomainClient()
+				if clientErr != nil {
+					return resource.NonRetryableError(clientErr)
+				}
+				err = conn.Call(context, connectivity.EndpointWaap, "DeleteDomain", func() (string, error) {
+					var requestId string
+					requestId, response, err = client.DeleteDomain(request)
+					return requestId, wangsuCommon.NewAPIError(err, requestId)
+				})
 				if err != nil {
 					return resource.NonRetryableError(err)
 				}
 				return nil
 			})
 			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
+				diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 				return diags
 			}
 			if response == nil {
@@ -3,10 +3,12 @@ package domain
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap"
 	waapDomain "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/domain"
 	"log"
@@ -19,8 +21,15 @@ func ResourceWaapDomainCopy() *schema.Resource {
 		DeleteContext: resourceWaapDomainCopyDelete,
 		ReadContext:   resourceWaapDomainCopyRead,
 		UpdateContext: resourceWaapDomainCopyUpdate,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"source_domain": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -40,6 +49,11 @@ func ResourceWaapDomainCopy() *schema.Resource {
 
 func resourceWaapDomainCopyCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_domain_copy.create")
+	unlock, lockErr := wangsuCommon.LockDomains(context, data.Get("target_domains").([]interface{}))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &waapDomain.UsingExistingHostnameToAddNewHostnameRequest{}
@@ -60,8 +74,17 @@ func resourceWaapDomainCopyCreate(context context.Context, data *schema.Resource
 
 	var response *waapDomain.UsingExistingHostnameToAddNewHostnameResponse
 	var err error
-	err = resource.RetryContext(context, time.Duration(5)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().AddDomainByCopy(request)
+	err = resource.RetryContext(context, data.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapDomainClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "AddDomainByCopy", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.AddDomainByCopy(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -69,7 +92,7 @@ func resourceWaapDomainCopyCreate(context context.Context, data *schema.Resource
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -78,46 +101,11 @@ func resourceWaapDomainCopyCreate(context context.Context, data *schema.Resource
 	}
 
 	// 等待接入完成
-	getRequest := &waapDomain.ListDomainInfoRequest{}
-	getResponse := &waapDomain.ListDomainInfoResponse{}
-	getRequest.SetDomainList(request.TargetDomains)
-	for {
-		err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-			_, getResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().GetDomainList(getRequest)
-			if err != nil {
-				return resource.NonRetryableError(err)
-			}
-			return nil
-		})
-
-		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
-			return diags
-		}
-
-		if getResponse == nil {
-			return nil
-		}
-		if len(getResponse.Data) == 0 {
-			time.Sleep(10 * time.Second)
-			continue
-		}
-		successDomains := make([]string, 0, len(getResponse.Data))
-		for _, v := range getResponse.Data {
-			successDomains = append(successDomains, *v.Domain)
-		}
-		targetDomainsStr := make([]string, len(request.TargetDomains))
-		for i, v := range request.TargetDomains {
-			targetDomainsStr[i] = *v
-		}
-		waitDomains := waap.Difference(targetDomainsStr, successDomains)
-		if len(waitDomains) == 0 {
-			log.Printf("resource.wangsu_waap_domain_copy.create: all domains are ready")
-			break
-		}
-		log.Printf("resource.wangsu_waap_domain_copy.create: waiting for domains %v", waitDomains)
-		time.Sleep(10 * time.Second)
+	if err = waitForDomainsReady(context, data, meta, request.TargetDomains, data.Timeout(schema.TimeoutCreate)); err != nil {
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+		return diags
 	}
+	log.Printf("resource.wangsu_waap_domain_copy.create: all domains are ready")
 
 	var ids = request.TargetDomains
 	ids = append(ids, request.SourceDomain)
@@ -129,8 +117,61 @@ func resourceWaapDomainCopyCreate(context context.Context, data *schema.Resource
 	return diags
 }
 
+// waitForDomainsReady polls the domain list until every domain of domains is
+// listed.
+func waitForDomainsReady(ctx context.Context, data *schema.ResourceData, meta interface{}, domains []*string, timeout time.Duration) error {
+	getRequest := &waapDomain.ListDomainInfoRequest{}
+	getRequest.SetDomainList(domains)
+	domainsStr := make([]string, len(domains))
+	for i, v := range domains {
+		domainsStr[i] = *v
+	}
+	waiter := &wangsuCommon.DeployWaiter{
+		Target:      []string{"READY"},
+		MinInterval: 5 * time.Second,
+		MaxInterval: 10 * time.Second,
+		Timeout:     timeout,
+		Description: fmt.Sprintf("access of domains %v", domainsStr),
+		Refresh: func(ctx context.Context) (string, string, error) {
+			conn := wangsuCommon.APIConn(data, meta)
+			client, err := conn.UseWaapDomainClient()
+			if err != nil {
+				return "", "", err
+			}
+			var getResponse *waapDomain.ListDomainInfoResponse
+			err = conn.Call(ctx, connectivity.EndpointWaap, "GetDomainList", func() (string, error) {
+				var requestId string
+				requestId, getResponse, err = client.GetDomainList(getRequest)
+				return requestId, wangsuCommon.NewAPIError(err, requestId)
+			})
+			if err != nil {
+				return "", "", err
+			}
+			if getResponse == nil {
+				return "READY", "", nil
+			}
+			successDomains := make([]string, 0, len(getResponse.Data))
+			for _, v := range getResponse.Data {
+				successDomains = append(successDomains, *v.Domain)
+			}
+			if waitDomains := waap.Difference(domainsStr, successDomains); len(waitDomains) > 0 {
+				log.Printf("resource.wangsu_waap_domain_copy.create: waiting for domains %v", waitDomains)
+				return "PENDING", "", nil
+			}
+			return "READY", "", nil
+		},
+	}
+	_, err := waiter.WaitForStatus(ctx)
+	return err
+}
+
 func resourceWaapDomainCopyDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_domain_copy.delete")
+	unlock, lockErr := wangsuCommon.LockDomains(context, data.Get("target_domains").([]interface{}))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *waapDomain.RemoveProtectedHostnameResponse
 	var err error
@@ -139,18 +180,27 @@ func resourceWaapDomainCopyDelete(context context.Context, data *schema.Resource
 		targetDomainsList := targetDomains.([]interface{})
 		for _, v := range targetDomainsList {
 			domain := v.(string)
-			err = resource.RetryContext(context, time.Duration(5)*time.Minute, func() *resource.RetryError {
+			err = resource.RetryContext(context, data.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
 				request := &waapDomain.RemoveProtectedHostnameParameters{
 					Domain: &domain,
 				}
-				_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapDomainClient().DeleteDomain(request)
+				conn := wangsuCommon.APIConn(data, meta)
+				client, clientErr := conn.UseWaapDomainClient()
+				if clientErr != nil {
+					return resource.NonRetryableError(clientErr)
+				}
+				err = conn.Call(context, connectivity.EndpointWaap, "DeleteDomain", func() (string, error) {
+					var requestId string
+					requestId, response, err = client.DeleteDomain(request)
+					return requestId, wangsuCommon.NewAPIError(err, requestId)
+				})
 				if err != nil {
 					return resource.NonRetryableError(err)
 				}
 				return nil
 			})
 			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
+				diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 				return diags
 			}
 			if response == nil {
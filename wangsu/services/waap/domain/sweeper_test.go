@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap/customizerule"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap/ratelimit"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap/whitelist"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/sweep"
+)
+
+// The rule sweepers are registered here rather than in their own packages, so
+// that they run in the same test binary as the domain sweeper that depends on
+// them.
+func init() {
+	resource.AddTestSweepers("wangsu_waap_whitelist", &resource.Sweeper{
+		Name: "wangsu_waap_whitelist",
+		F: func(region string) error {
+			return sweepRules(region, "WAAP whitelist rule", whitelist.DataSourceWaapWhitelists(), whitelist.ResourceWaapWhitelist())
+		},
+	})
+	resource.AddTestSweepers("wangsu_waap_ratelimit", &resource.Sweeper{
+		Name: "wangsu_waap_ratelimit",
+		F: func(region string) error {
+			return sweepRules(region, "WAAP rate limiting rule", ratelimit.DataSourceRateLimits(), ratelimit.ResourceWaapRateLimit())
+		},
+	})
+	resource.AddTestSweepers("wangsu_waap_customizerule", &resource.Sweeper{
+		Name: "wangsu_waap_customizerule",
+		F: func(region string) error {
+			return sweepRules(region, "WAAP custom rule", customizerule.DataSourceCustomizeRules(), customizerule.ResourceWaapCustomizeRule())
+		},
+	})
+	resource.AddTestSweepers("wangsu_waap_domain", &resource.Sweeper{
+		Name: "wangsu_waap_domain",
+		F:    sweepWaapDomains,
+		// the rules of a domain go first
+		Dependencies: []string{"wangsu_waap_whitelist", "wangsu_waap_ratelimit", "wangsu_waap_customizerule"},
+	})
+}
+
+// TestMain runs the sweepers when the tests are started with -sweep, e.g.
+// go test ./wangsu/services/... -sweep=all.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func sweepWaapDomains(region string) error {
+	meta, err := sweep.SharedMeta(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	domains, err := listWaapDomains(ctx, meta)
+	if err != nil {
+		return err
+	}
+
+	var errs sweep.Errors
+	for _, domain := range domains {
+		if !sweep.HasPrefix(domain) {
+			continue
+		}
+		log.Printf("[INFO] Deleting WAAP domain %s", domain)
+		errs.Add("WAAP domain", domain, sweep.Delete(ctx, ResourceWaapDomain(), domain, map[string]interface{}{
+			"target_domains": []interface{}{domain},
+		}, meta))
+	}
+	return errs.Err()
+}
+
+func listWaapDomains(ctx context.Context, meta interface{}) ([]string, error) {
+	data, err := sweep.Read(ctx, DataSourceWaapDomains(), nil, meta)
+	if err != nil {
+		return nil, err
+	}
+	var domains []string
+	for _, item := range data.Get("data").([]interface{}) {
+		domains = append(domains, item.(map[string]interface{})["domain"].(string))
+	}
+	return domains, nil
+}
+
+// sweepRules deletes the rules listed by dataSource, such as wangsu_waap_whitelists,
+// whose name or description starts with the sweep prefix or which protect a
+// domain that does. res is the rule resource, its delete function reads the id
+// and domain of the rule.
+func sweepRules(region, kind string, dataSource, res *schema.Resource) error {
+	meta, err := sweep.SharedMeta(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	domains, err := listWaapDomains(ctx, meta)
+	if err != nil || len(domains) == 0 {
+		return err
+	}
+	domainList := make([]interface{}, len(domains))
+	for i, domain := range domains {
+		domainList[i] = domain
+	}
+	data, err := sweep.Read(ctx, dataSource, map[string]interface{}{"domain_list": domainList}, meta)
+	if err != nil {
+		return err
+	}
+
+	var errs sweep.Errors
+	for _, item := range data.Get("data").([]interface{}) {
+		rule := item.(map[string]interface{})
+		if !sweep.HasPrefix(rule["rule_name"], rule["description"], rule["domain"]) {
+			continue
+		}
+		id := rule["id"].(string)
+		log.Printf("[INFO] Deleting %s %s (%s) of %s", kind, id, rule["rule_name"], rule["domain"])
+		errs.Add(kind, id, sweep.Delete(ctx, res, id, map[string]interface{}{
+			"domain": rule["domain"],
+		}, meta))
+	}
+	return errs.Err()
+}
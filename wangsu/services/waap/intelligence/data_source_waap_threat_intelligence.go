@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	securityPolicy "github.com/wangsu-api/wangsu-sdk-go/wangsu/securitypolicy"
 	"log"
 	"time"
@@ -17,6 +18,7 @@ func DataSourceWaapThreatIntelligence() *schema.Resource {
 		ReadContext: dataSourceWaapThreatIntelligenceRead,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain_list": {
 				Type:        schema.TypeList,
 				Required:    true,
@@ -91,7 +93,16 @@ func dataSourceWaapThreatIntelligenceRead(context context.Context, data *schema.
 	var response *securityPolicy.GetThreatIntelligenceDomainConfigResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().GetThreatIntelligenceDomainConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetThreatIntelligenceDomainConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetThreatIntelligenceDomainConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -99,7 +110,7 @@ func dataSourceWaapThreatIntelligenceRead(context context.Context, data *schema.
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
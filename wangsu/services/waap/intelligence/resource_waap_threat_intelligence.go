@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	securityPolicy "github.com/wangsu-api/wangsu-sdk-go/wangsu/securitypolicy"
 	"log"
 	"time"
@@ -26,6 +27,7 @@ func ResourceWaapThreatIntelligence() *schema.Resource {
 		},
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -73,7 +75,16 @@ func resourceWaapThreatIntelligenceRead(context context.Context, data *schema.Re
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
 		domain := data.Id()
 		request.SetDomainList([]*string{&domain})
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().GetThreatIntelligenceDomainConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetThreatIntelligenceDomainConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetThreatIntelligenceDomainConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -81,7 +92,7 @@ func resourceWaapThreatIntelligenceRead(context context.Context, data *schema.Re
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil || len(response.Data) == 0 {
@@ -125,6 +136,11 @@ func resourceWaapThreatIntelligenceRead(context context.Context, data *schema.Re
 
 func resourceWaapThreatIntelligenceUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_threat_intelligence.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	// 把 domain 强制刷回旧值，否则会有权限问题
@@ -156,7 +172,16 @@ func resourceWaapThreatIntelligenceUpdate(context context.Context, data *schema.
 	var response *securityPolicy.UpdateThreatIntelligenceDomainConfigResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().UpdateThreatIntelligenceDomainConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateThreatIntelligenceDomainConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateThreatIntelligenceDomainConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -164,7 +189,7 @@ func resourceWaapThreatIntelligenceUpdate(context context.Context, data *schema.
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -0,0 +1,49 @@
+package pre_deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
+	preDeploy "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/predeploy"
+)
+
+// preDeployTimeout is the default time allowed for a pre-deployment to finish.
+const preDeployTimeout = 30 * time.Minute
+
+// waitForPreDeployResult polls a pre-deployment until it succeeds and returns the
+// last result. A failed pre-deployment is reported at once.
+func waitForPreDeployResult(ctx context.Context, conn *connectivity.WangSuClient, preId *string, timeout time.Duration) (*preDeploy.GetPreDeployResultResponse, error) {
+	var response *preDeploy.GetPreDeployResultResponse
+	request := &preDeploy.GetPreDeployResultRequest{PreId: preId}
+	waiter := &wangsuCommon.DeployWaiter{
+		Target:      []string{"SUCCESS"},
+		Failure:     []string{"FAIL"},
+		MinInterval: 2 * time.Second,
+		MaxInterval: 10 * time.Second,
+		Timeout:     timeout,
+		Description: fmt.Sprintf("pre-deployment %s", *preId),
+		Refresh: func(ctx context.Context) (string, string, error) {
+			client, err := conn.UseWaapPreDeployClient()
+			if err != nil {
+				return "", "", err
+			}
+			err = conn.Call(ctx, connectivity.EndpointWaap, "GetPreDeployResult", func() (string, error) {
+				var requestId string
+				requestId, response, err = client.GetPreDeployResult(request)
+				return requestId, wangsuCommon.NewAPIError(err, requestId)
+			})
+			if err != nil {
+				return "", "", err
+			}
+			if response == nil || response.Data == nil || response.Data.DeployStatus == nil {
+				return "SUCCESS", "", nil
+			}
+			return *response.Data.DeployStatus, "", nil
+		},
+	}
+	_, err := waiter.WaitForStatus(ctx)
+	return response, err
+}
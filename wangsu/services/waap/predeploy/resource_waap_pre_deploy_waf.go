@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/services/waap"
 	preDeploy "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/predeploy"
 	"log"
@@ -21,7 +22,15 @@ func ResourceWaapPreDeployWAF() *schema.Resource {
 		UpdateContext: resourceWaapPreDeployWAFCreate,
 		DeleteContext: resourceWaapPreDeployWAFRead,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(preDeployTimeout),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(preDeployTimeout),
+			Delete: schema.DefaultTimeout(1 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"host_list": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -124,6 +133,11 @@ func ResourceWaapPreDeployWAF() *schema.Resource {
 
 func resourceWaapPreDeployWAFCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Println("resource.wangsu_pre_deploy_waf.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 
@@ -182,7 +196,16 @@ func resourceWaapPreDeployWAFCreate(context context.Context, data *schema.Resour
 	var response *preDeploy.PreDeployWAFConfigurationResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapPreDeployClient().PreDeployWAFConfiguration(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapPreDeployClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "PreDeployWAFConfiguration", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.PreDeployWAFConfiguration(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -190,7 +213,7 @@ func resourceWaapPreDeployWAFCreate(context context.Context, data *schema.Resour
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -201,45 +224,27 @@ func resourceWaapPreDeployWAFCreate(context context.Context, data *schema.Resour
 	data.SetId(*response.Data.PreId)
 
 	// Poll for deployment result
-	getRequest := &preDeploy.GetPreDeployResultRequest{}
-	getResponse := &preDeploy.GetPreDeployResultResponse{}
-	getRequest.PreId = response.Data.PreId
-	for {
-		err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-			_, getResponse, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapPreDeployClient().GetPreDeployResult(getRequest)
-			if err != nil {
-				return resource.NonRetryableError(err)
-			}
-			return nil
-		})
-
-		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
-			return diags
-		}
-
-		if getResponse == nil {
-			return nil
-		}
+	timeout := data.Timeout(schema.TimeoutCreate)
+	if !data.IsNewResource() {
+		timeout = data.Timeout(schema.TimeoutUpdate)
+	}
+	getResponse, err := waitForPreDeployResult(context, wangsuCommon.APIConn(data, meta), response.Data.PreId, timeout)
+	if err != nil {
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
+		return diags
+	}
+	if getResponse == nil || getResponse.Data == nil {
+		return nil
+	}
 
-		if *getResponse.Data.DeployStatus == "SUCCESS" {
-			hostList := make([]map[string]interface{}, len(getResponse.Data.HostList))
-			for i, host := range getResponse.Data.HostList {
-				hostList[i] = map[string]interface{}{
-					"host_name":    host.HostName,
-					"host_address": host.HostAddress,
-				}
-			}
-			_ = data.Set("host_list", hostList)
-			break
-		} else if *getResponse.Data.DeployStatus == "FAIL" {
-			log.Println("Deployment failed!")
-			break
-		} else {
-			log.Println("Deployment in progress, retrying...")
-			time.Sleep(10 * time.Second)
+	hostList := make([]map[string]interface{}, len(getResponse.Data.HostList))
+	for i, host := range getResponse.Data.HostList {
+		hostList[i] = map[string]interface{}{
+			"host_name":    host.HostName,
+			"host_address": host.HostAddress,
 		}
 	}
+	_ = data.Set("host_list", hostList)
 
 	return diags
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapRatelimit "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/ratelimit"
 	"log"
 	"time"
@@ -20,6 +21,7 @@ func ResourceWaapRateLimit() *schema.Resource {
 		DeleteContext: resourceWaapRateLimitDelete,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -425,6 +427,11 @@ func ResourceWaapRateLimit() *schema.Resource {
 
 func resourceWaapRateLimitCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_ratelimit.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &waapRatelimit.CreatRateLimitingRuleRequest{}
@@ -771,7 +778,16 @@ func resourceWaapRateLimitCreate(context context.Context, data *schema.ResourceD
 	var response *waapRatelimit.CreatRateLimitingRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapRatelimitClient().AddRateLimit(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapRatelimitClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "AddRateLimit", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.AddRateLimit(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -779,7 +795,7 @@ func resourceWaapRateLimitCreate(context context.Context, data *schema.ResourceD
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -806,7 +822,16 @@ func resourceWaapRateLimitRead(context context.Context, data *schema.ResourceDat
 			DomainList: []*string{&domain},
 			//RuleName:   &ruleName,
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapRatelimitClient().GetRateLimitList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapRatelimitClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetRateLimitList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetRateLimitList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -814,216 +839,216 @@ func resourceWaapRateLimitRead(context context.Context, data *schema.ResourceDat
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_ratelimit")
 	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
-			}
-			_ = data.Set("domain", item.Domain)
-			_ = data.Set("rule_name", item.RuleName)
-			_ = data.Set("description", item.Description)
-			_ = data.Set("scene", item.Scene)
-			_ = data.Set("statistical_item", item.StatisticalItem)
-			_ = data.Set("statistics_key", item.StatisticsKey)
-			_ = data.Set("statistical_period", item.StatisticalPeriod)
-			_ = data.Set("trigger_threshold", item.TriggerThreshold)
-			_ = data.Set("intercept_time", item.InterceptTime)
-			_ = data.Set("effective_status", item.EffectiveStatus)
-			_ = data.Set("asset_api_id", item.AssetApiId)
-			_ = data.Set("action", item.Action)
+	item := response.Data[index]
+	_ = data.Set("domain", item.Domain)
+	_ = data.Set("rule_name", item.RuleName)
+	_ = data.Set("description", item.Description)
+	_ = data.Set("scene", item.Scene)
+	_ = data.Set("statistical_item", item.StatisticalItem)
+	_ = data.Set("statistics_key", item.StatisticsKey)
+	_ = data.Set("statistical_period", item.StatisticalPeriod)
+	_ = data.Set("trigger_threshold", item.TriggerThreshold)
+	_ = data.Set("intercept_time", item.InterceptTime)
+	_ = data.Set("effective_status", item.EffectiveStatus)
+	_ = data.Set("asset_api_id", item.AssetApiId)
+	_ = data.Set("action", item.Action)
 
-			if item.RateLimitEffective != nil {
-				rateLimitEffective := map[string]interface{}{
-					"effective": item.RateLimitEffective.Effective,
-					"start":     item.RateLimitEffective.Start,
-					"end":       item.RateLimitEffective.End,
-					"timezone":  item.RateLimitEffective.Timezone,
-				}
-				_ = data.Set("rate_limit_effective", []interface{}{rateLimitEffective})
-			}
+	if item.RateLimitEffective != nil {
+		rateLimitEffective := map[string]interface{}{
+			"effective": item.RateLimitEffective.Effective,
+			"start":     item.RateLimitEffective.Start,
+			"end":       item.RateLimitEffective.End,
+			"timezone":  item.RateLimitEffective.Timezone,
+		}
+		_ = data.Set("rate_limit_effective", []interface{}{rateLimitEffective})
+	}
 
-			rateLimitRuleCondition := make([]interface{}, 0)
-			if item.RateLimitRuleCondition != nil {
-				condition := make(map[string]interface{})
+	rateLimitRuleCondition := make([]interface{}, 0)
+	if item.RateLimitRuleCondition != nil {
+		condition := make(map[string]interface{})
 
-				if item.RateLimitRuleCondition.IpOrIpsConditions != nil {
-					ipOrIpsConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.IpOrIpsConditions {
-						ipOrIpsCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"ip_or_ips":  v.IpOrIps,
-						}
-						ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
-					}
-					condition["ip_or_ips_conditions"] = ipOrIpsConditions
+		if item.RateLimitRuleCondition.IpOrIpsConditions != nil {
+			ipOrIpsConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.IpOrIpsConditions {
+				ipOrIpsCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"ip_or_ips":  v.IpOrIps,
 				}
+				ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
+			}
+			condition["ip_or_ips_conditions"] = ipOrIpsConditions
+		}
 
-				if item.RateLimitRuleCondition.PathConditions != nil {
-					pathConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.PathConditions {
-						pathCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"paths":      v.Paths,
-						}
-						pathConditions = append(pathConditions, pathCondition)
-					}
-					condition["path_conditions"] = pathConditions
+		if item.RateLimitRuleCondition.PathConditions != nil {
+			pathConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.PathConditions {
+				pathCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"paths":      v.Paths,
 				}
+				pathConditions = append(pathConditions, pathCondition)
+			}
+			condition["path_conditions"] = pathConditions
+		}
 
-				if item.RateLimitRuleCondition.UriConditions != nil {
-					uriConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.UriConditions {
-						uriCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"uri":        v.Uri,
-						}
-						uriConditions = append(uriConditions, uriCondition)
-					}
-					condition["uri_conditions"] = uriConditions
+		if item.RateLimitRuleCondition.UriConditions != nil {
+			uriConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.UriConditions {
+				uriCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"uri":        v.Uri,
 				}
+				uriConditions = append(uriConditions, uriCondition)
+			}
+			condition["uri_conditions"] = uriConditions
+		}
 
-				if item.RateLimitRuleCondition.UriParamConditions != nil {
-					uriParamConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.UriParamConditions {
-						uriParamCondition := map[string]interface{}{
-							"match_type":  v.MatchType,
-							"param_name":  v.ParamName,
-							"param_value": v.ParamValue,
-						}
-						uriParamConditions = append(uriParamConditions, uriParamCondition)
-					}
-					condition["uri_param_conditions"] = uriParamConditions
+		if item.RateLimitRuleCondition.UriParamConditions != nil {
+			uriParamConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.UriParamConditions {
+				uriParamCondition := map[string]interface{}{
+					"match_type":  v.MatchType,
+					"param_name":  v.ParamName,
+					"param_value": v.ParamValue,
 				}
+				uriParamConditions = append(uriParamConditions, uriParamCondition)
+			}
+			condition["uri_param_conditions"] = uriParamConditions
+		}
 
-				if item.RateLimitRuleCondition.UaConditions != nil {
-					uaConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.UaConditions {
-						uaCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"ua":         v.Ua,
-						}
-						uaConditions = append(uaConditions, uaCondition)
-					}
-					condition["ua_conditions"] = uaConditions
+		if item.RateLimitRuleCondition.UaConditions != nil {
+			uaConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.UaConditions {
+				uaCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"ua":         v.Ua,
 				}
+				uaConditions = append(uaConditions, uaCondition)
+			}
+			condition["ua_conditions"] = uaConditions
+		}
 
-				if item.RateLimitRuleCondition.RefererConditions != nil {
-					refererConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.RefererConditions {
-						refererCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"referer":    v.Referer,
-						}
-						refererConditions = append(refererConditions, refererCondition)
-					}
-					condition["referer_conditions"] = refererConditions
+		if item.RateLimitRuleCondition.RefererConditions != nil {
+			refererConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.RefererConditions {
+				refererCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"referer":    v.Referer,
 				}
+				refererConditions = append(refererConditions, refererCondition)
+			}
+			condition["referer_conditions"] = refererConditions
+		}
 
-				if item.RateLimitRuleCondition.HeaderConditions != nil {
-					headerConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.HeaderConditions {
-						headerCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"key":        v.Key,
-							"value_list": v.ValueList,
-						}
-						headerConditions = append(headerConditions, headerCondition)
-					}
-					condition["header_conditions"] = headerConditions
+		if item.RateLimitRuleCondition.HeaderConditions != nil {
+			headerConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.HeaderConditions {
+				headerCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"key":        v.Key,
+					"value_list": v.ValueList,
 				}
+				headerConditions = append(headerConditions, headerCondition)
+			}
+			condition["header_conditions"] = headerConditions
+		}
 
-				if item.RateLimitRuleCondition.AreaConditions != nil {
-					areaConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.AreaConditions {
-						areaCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"areas":      v.Areas,
-						}
-						areaConditions = append(areaConditions, areaCondition)
-					}
-					condition["area_conditions"] = areaConditions
+		if item.RateLimitRuleCondition.AreaConditions != nil {
+			areaConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.AreaConditions {
+				areaCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"areas":      v.Areas,
 				}
+				areaConditions = append(areaConditions, areaCondition)
+			}
+			condition["area_conditions"] = areaConditions
+		}
 
-				if item.RateLimitRuleCondition.StatusCodeConditions != nil {
-					statusCodeConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.StatusCodeConditions {
-						statusCodeCondition := map[string]interface{}{
-							"match_type":  v.MatchType,
-							"status_code": v.StatusCode,
-						}
-						statusCodeConditions = append(statusCodeConditions, statusCodeCondition)
-					}
-					condition["status_code_conditions"] = statusCodeConditions
+		if item.RateLimitRuleCondition.StatusCodeConditions != nil {
+			statusCodeConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.StatusCodeConditions {
+				statusCodeCondition := map[string]interface{}{
+					"match_type":  v.MatchType,
+					"status_code": v.StatusCode,
 				}
+				statusCodeConditions = append(statusCodeConditions, statusCodeCondition)
+			}
+			condition["status_code_conditions"] = statusCodeConditions
+		}
 
-				if item.RateLimitRuleCondition.MethodConditions != nil {
-					methodConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.MethodConditions {
-						methodCondition := map[string]interface{}{
-							"match_type":     v.MatchType,
-							"request_method": v.RequestMethod,
-						}
-						methodConditions = append(methodConditions, methodCondition)
-					}
-					condition["method_conditions"] = methodConditions
-				}
-				// Scheme Conditions
-				if item.RateLimitRuleCondition.SchemeConditions != nil {
-					schemeConditions := make([]interface{}, 0)
-					for _, v := range item.RateLimitRuleCondition.SchemeConditions {
-						schemeCondition := map[string]interface{}{
-							"match_type": v.MatchType,
-							"scheme":     v.Scheme,
-						}
-						schemeConditions = append(schemeConditions, schemeCondition)
-					}
-					condition["scheme_conditions"] = schemeConditions
+		if item.RateLimitRuleCondition.MethodConditions != nil {
+			methodConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.MethodConditions {
+				methodCondition := map[string]interface{}{
+					"match_type":     v.MatchType,
+					"request_method": v.RequestMethod,
 				}
+				methodConditions = append(methodConditions, methodCondition)
+			}
+			condition["method_conditions"] = methodConditions
+		}
+		// Scheme Conditions
+		if item.RateLimitRuleCondition.SchemeConditions != nil {
+			schemeConditions := make([]interface{}, 0)
+			for _, v := range item.RateLimitRuleCondition.SchemeConditions {
+				schemeCondition := map[string]interface{}{
+					"match_type": v.MatchType,
+					"scheme":     v.Scheme,
+				}
+				schemeConditions = append(schemeConditions, schemeCondition)
+			}
+			condition["scheme_conditions"] = schemeConditions
+		}
 
-				if item.RateLimitRuleCondition.Ja3Conditions != nil {
-					ja3Conditions := make([]interface{}, 0)
-					for _, condition := range item.RateLimitRuleCondition.Ja3Conditions {
-						ja3Condition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ja3_list":   condition.Ja3List,
-						}
-						ja3Conditions = append(ja3Conditions, ja3Condition)
-					}
-					condition["ja3_conditions"] = ja3Conditions
-				}
-				if item.RateLimitRuleCondition.Ja4Conditions != nil {
-					ja4Conditions := make([]interface{}, 0)
-					for _, condition := range item.RateLimitRuleCondition.Ja4Conditions {
-						ja4Condition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ja4_list":   condition.Ja4List,
-						}
-						ja4Conditions = append(ja4Conditions, ja4Condition)
-					}
-					condition["ja4_conditions"] = ja4Conditions
-				}
-				rateLimitRuleCondition = append(rateLimitRuleCondition, condition)
+		if item.RateLimitRuleCondition.Ja3Conditions != nil {
+			ja3Conditions := make([]interface{}, 0)
+			for _, condition := range item.RateLimitRuleCondition.Ja3Conditions {
+				ja3Condition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ja3_list":   condition.Ja3List,
+				}
+				ja3Conditions = append(ja3Conditions, ja3Condition)
+			}
+			condition["ja3_conditions"] = ja3Conditions
+		}
+		if item.RateLimitRuleCondition.Ja4Conditions != nil {
+			ja4Conditions := make([]interface{}, 0)
+			for _, condition := range item.RateLimitRuleCondition.Ja4Conditions {
+				ja4Condition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ja4_list":   condition.Ja4List,
+				}
+				ja4Conditions = append(ja4Conditions, ja4Condition)
 			}
-			_ = data.Set("rate_limit_rule_condition", rateLimitRuleCondition)
+			condition["ja4_conditions"] = ja4Conditions
 		}
+		rateLimitRuleCondition = append(rateLimitRuleCondition, condition)
 	}
+	_ = data.Set("rate_limit_rule_condition", rateLimitRuleCondition)
 	return nil
 }
 
 func resourceWaapRateLimitUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_ratelimit.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 	var diags diag.Diagnostics
 	if data.HasChange("domain") {
 		// 把domain强制刷回旧值，否则会有权限问题
@@ -1378,14 +1403,23 @@ func resourceWaapRateLimitUpdate(context context.Context, data *schema.ResourceD
 	var response *waapRatelimit.UpdateRateLimitingRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapRatelimitClient().UpdateRateLimit(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapRatelimitClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateRateLimit", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateRateLimit(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -1397,6 +1431,11 @@ func resourceWaapRateLimitUpdate(context context.Context, data *schema.ResourceD
 
 func resourceWaapRateLimitDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_ratelimit.delete")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *waapRatelimit.DeleteRateLimitingRulesResponse
 	var err error
@@ -1406,14 +1445,23 @@ func resourceWaapRateLimitDelete(context context.Context, data *schema.ResourceD
 		request := &waapRatelimit.DeleteRateLimitingRulesRequest{
 			Ids: []*string{&id},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapRatelimitClient().DeleteRateLimit(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapRatelimitClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "DeleteRateLimit", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.DeleteRateLimit(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -0,0 +1,65 @@
+package ratelimit_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccWaapRatelimit_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			if n := server.Collection("waap_ratelimit").Len(); n > 0 {
+				return fmt.Errorf("%d rate limiting rules left after destroy", n)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccWaapRatelimitConfig(1000),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("wangsu_waap_ratelimit.test", "id"),
+					resource.TestCheckResourceAttr("wangsu_waap_ratelimit.test", "trigger_threshold", "1000"),
+					resource.TestCheckResourceAttr("wangsu_waap_ratelimit.test", "rate_limit_rule_condition.0.ip_or_ips_conditions.0.ip_or_ips.#", "2"),
+				),
+			},
+			{
+				Config: server.ProviderConfig() + testAccWaapRatelimitConfig(2000),
+				Check:  resource.TestCheckResourceAttr("wangsu_waap_ratelimit.test", "trigger_threshold", "2000"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccWaapRatelimitConfig(threshold int) string {
+	return fmt.Sprintf(`
+resource "wangsu_waap_ratelimit" "test" {
+  domain             = "tf-acc-test.example.com"
+  rule_name          = "tf_acc_test"
+  scene              = "WEB"
+  statistical_item   = "IP"
+  statistical_period = 60
+  trigger_threshold  = %d
+  intercept_time     = 600
+  effective_status   = "PERMANENT"
+  action             = "BLOCK"
+
+  rate_limit_rule_condition {
+    ip_or_ips_conditions {
+      match_type = "EQUAL"
+      ip_or_ips  = ["192.0.2.1", "198.51.100.0/24"]
+    }
+  }
+}
+`, threshold)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapShareCustomizeBot "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/share-customizebot"
 	"log"
 	"time"
@@ -16,6 +17,7 @@ func DataSourceShareCustomizeBot() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceShareCustomizeBotRead,
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"bot_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -107,7 +109,16 @@ func dataSourceShareCustomizeBotRead(ctx context.Context, data *schema.ResourceD
 		request.SetBotName(v.(string))
 	}
 	err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeBotClient().GetList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointWaap, "GetList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -115,7 +126,7 @@ func dataSourceShareCustomizeBotRead(ctx context.Context, data *schema.ResourceD
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
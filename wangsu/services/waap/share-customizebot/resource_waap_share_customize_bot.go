@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapShareCustomizeBot "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/share-customizebot"
 	"log"
 	"time"
@@ -19,6 +20,7 @@ func ResourceWaapShareCustomizeBot() *schema.Resource {
 		DeleteContext: resourceWaapShareCustomizeBotDelete,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -148,7 +150,16 @@ func resourceWaapShareCustomizeBotCreate(ctx context.Context, data *schema.Resou
 	var response *waapShareCustomizeBot.AddShareCustomizeBotTFResponse
 	var err error
 	err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeBotClient().Add(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointWaap, "Add", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Add(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -156,7 +167,7 @@ func resourceWaapShareCustomizeBotCreate(ctx context.Context, data *schema.Resou
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -178,7 +189,16 @@ func resourceWaapShareCustomizeBotRead(ctx context.Context, data *schema.Resourc
 	var diags diag.Diagnostics
 	err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
 		request := &waapShareCustomizeBot.ListShareCustomizeBotsRequest{}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeBotClient().GetList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointWaap, "GetList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -186,46 +206,41 @@ func resourceWaapShareCustomizeBotRead(ctx context.Context, data *schema.Resourc
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_share_customizebot")
 	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
-			}
-			_ = data.Set("id", item.Id)
-			_ = data.Set("rela_domain_list", item.RelaDomainList)
-			_ = data.Set("bot_name", item.BotName)
-			_ = data.Set("bot_description", item.BotDescription)
-			_ = data.Set("bot_act", item.BotAct)
-			// 映射 conditions 数据
-			conditions := make([]map[string]interface{}, len(item.ConditionList))
-			for i, cond := range item.ConditionList {
-				conditions[i] = map[string]interface{}{
-					"condition_name": *cond.ConditionName,
-					"condition_func": *cond.ConditionFunc,
-					"condition_key": func() string {
-						if cond.ConditionKey != nil {
-							return *cond.ConditionKey
-						}
-						return ""
-					}(),
-					"condition_value_list": cond.ConditionValueList,
+	item := response.Data[index]
+	_ = data.Set("id", item.Id)
+	_ = data.Set("rela_domain_list", item.RelaDomainList)
+	_ = data.Set("bot_name", item.BotName)
+	_ = data.Set("bot_description", item.BotDescription)
+	_ = data.Set("bot_act", item.BotAct)
+	// 映射 conditions 数据
+	conditions := make([]map[string]interface{}, len(item.ConditionList))
+	for i, cond := range item.ConditionList {
+		conditions[i] = map[string]interface{}{
+			"condition_name": *cond.ConditionName,
+			"condition_func": *cond.ConditionFunc,
+			"condition_key": func() string {
+				if cond.ConditionKey != nil {
+					return *cond.ConditionKey
 				}
-			}
-			_ = data.Set("condition_list", conditions)
+				return ""
+			}(),
+			"condition_value_list": cond.ConditionValueList,
 		}
 	}
+	_ = data.Set("condition_list", conditions)
 	return nil
 }
 
@@ -290,14 +305,23 @@ func resourceWaapShareCustomizeBotUpdate(ctx context.Context, data *schema.Resou
 	var response *waapShareCustomizeBot.UpdateShareCustomizeBotTFResponse
 	var err error
 	err = resource.RetryContext(ctx, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeBotClient().Update(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointWaap, "Update", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Update(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -318,14 +342,23 @@ func resourceWaapShareCustomizeBotDelete(ctx context.Context, data *schema.Resou
 		request := &waapShareCustomizeBot.DeleteShareCustomizeBotsRequest{
 			IdList: []*string{&id},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeBotClient().Delete(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeBotClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(ctx, connectivity.EndpointWaap, "Delete", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Delete(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
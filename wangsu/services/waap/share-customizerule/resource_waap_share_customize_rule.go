@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapShareCustomizerule "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/share-customizerule"
 	"log"
 	"time"
@@ -19,6 +20,7 @@ func ResourceWaapShareCustomizeRule() *schema.Resource {
 		DeleteContext: resourceWaapShareCustomizeRuleDelete,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -552,7 +554,16 @@ func resourceWaapShareCustomizeRuleCreate(context context.Context, data *schema.
 	var response *waapShareCustomizerule.CreateSharedCustomRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeruleClient().Add(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "Add", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Add(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -560,7 +571,7 @@ func resourceWaapShareCustomizeRuleCreate(context context.Context, data *schema.
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -580,7 +591,16 @@ func resourceWaapShareCustomizeRuleRead(context context.Context, data *schema.Re
 	var diags diag.Diagnostics
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
 		request := &waapShareCustomizerule.ListSharedCustomRulesRequest{}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeruleClient().GetList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -588,145 +608,140 @@ func resourceWaapShareCustomizeRuleRead(context context.Context, data *schema.Re
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_share_customizerule")
 	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
+	item := response.Data[index]
+	_ = data.Set("id", item.Id)
+	_ = data.Set("relation_domain_list", item.RelationDomainList)
+	_ = data.Set("rule_name", item.RuleName)
+	_ = data.Set("description", item.Description)
+	_ = data.Set("act", item.Act)
+	condition := make(map[string]interface{})
+	if item.Condition != nil {
+		if item.Condition.IpOrIpsConditions != nil {
+			ipOrIpsConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.IpOrIpsConditions {
+				ipOrIpsCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ip_or_ips":  condition.IpOrIps,
+				}
+				ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
+			}
+			condition["ip_or_ips_conditions"] = ipOrIpsConditions
+		}
+		if item.Condition.PathConditions != nil {
+			pathConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.PathConditions {
+				pathCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"paths":      condition.Paths,
+				}
+				pathConditions = append(pathConditions, pathCondition)
 			}
-			_ = data.Set("id", item.Id)
-			_ = data.Set("relation_domain_list", item.RelationDomainList)
-			_ = data.Set("rule_name", item.RuleName)
-			_ = data.Set("description", item.Description)
-			_ = data.Set("act", item.Act)
-			condition := make(map[string]interface{})
-			if item.Condition != nil {
-				if item.Condition.IpOrIpsConditions != nil {
-					ipOrIpsConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.IpOrIpsConditions {
-						ipOrIpsCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ip_or_ips":  condition.IpOrIps,
-						}
-						ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
-					}
-					condition["ip_or_ips_conditions"] = ipOrIpsConditions
-				}
-				if item.Condition.PathConditions != nil {
-					pathConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.PathConditions {
-						pathCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"paths":      condition.Paths,
-						}
-						pathConditions = append(pathConditions, pathCondition)
-					}
-					condition["path_conditions"] = pathConditions
-				}
-				if item.Condition.UriConditions != nil {
-					uriConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.UriConditions {
-						uriCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"uri":        condition.Uri,
-						}
-						uriConditions = append(uriConditions, uriCondition)
-					}
-					condition["uri_conditions"] = uriConditions
-				}
-				if item.Condition.UaConditions != nil {
-					uaConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.UaConditions {
-						uaCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ua":         condition.Ua,
-						}
-						uaConditions = append(uaConditions, uaCondition)
-					}
-					condition["ua_conditions"] = uaConditions
-				}
-				if item.Condition.RefererConditions != nil {
-					refererConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.RefererConditions {
-						refererCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"referer":    condition.Referer,
-						}
-						refererConditions = append(refererConditions, refererCondition)
-					}
-					condition["referer_conditions"] = refererConditions
-				}
-				if item.Condition.HeaderConditions != nil {
-					headerConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.HeaderConditions {
-						headerCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"key":        condition.Key,
-							"value_list": condition.ValueList,
-						}
-						headerConditions = append(headerConditions, headerCondition)
-					}
-					condition["header_conditions"] = headerConditions
-				}
-				if item.Condition.AreaConditions != nil {
-					areaConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.AreaConditions {
-						areaCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"areas":      condition.Areas,
-						}
-						areaConditions = append(areaConditions, areaCondition)
-					}
-					condition["area_conditions"] = areaConditions
-				}
-				if item.Condition.MethodConditions != nil {
-					methodConditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.MethodConditions {
-						methodCondition := map[string]interface{}{
-							"match_type":     condition.MatchType,
-							"request_method": condition.RequestMethod,
-						}
-						methodConditions = append(methodConditions, methodCondition)
-					}
-					condition["method_conditions"] = methodConditions
-				}
-				if item.Condition.Ja3Conditions != nil {
-					ja3Conditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.Ja3Conditions {
-						ja3Condition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ja3_list":   condition.Ja3List,
-						}
-						ja3Conditions = append(ja3Conditions, ja3Condition)
-					}
-					condition["ja3_conditions"] = ja3Conditions
-				}
-				if item.Condition.Ja4Conditions != nil {
-					ja4Conditions := make([]interface{}, 0)
-					for _, condition := range item.Condition.Ja4Conditions {
-						ja4Condition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ja4_list":   condition.Ja4List,
-						}
-						ja4Conditions = append(ja4Conditions, ja4Condition)
-					}
-					condition["ja4_conditions"] = ja4Conditions
+			condition["path_conditions"] = pathConditions
+		}
+		if item.Condition.UriConditions != nil {
+			uriConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.UriConditions {
+				uriCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"uri":        condition.Uri,
+				}
+				uriConditions = append(uriConditions, uriCondition)
+			}
+			condition["uri_conditions"] = uriConditions
+		}
+		if item.Condition.UaConditions != nil {
+			uaConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.UaConditions {
+				uaCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ua":         condition.Ua,
 				}
+				uaConditions = append(uaConditions, uaCondition)
 			}
-			_ = data.Set("condition", condition)
+			condition["ua_conditions"] = uaConditions
+		}
+		if item.Condition.RefererConditions != nil {
+			refererConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.RefererConditions {
+				refererCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"referer":    condition.Referer,
+				}
+				refererConditions = append(refererConditions, refererCondition)
+			}
+			condition["referer_conditions"] = refererConditions
+		}
+		if item.Condition.HeaderConditions != nil {
+			headerConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.HeaderConditions {
+				headerCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"key":        condition.Key,
+					"value_list": condition.ValueList,
+				}
+				headerConditions = append(headerConditions, headerCondition)
+			}
+			condition["header_conditions"] = headerConditions
+		}
+		if item.Condition.AreaConditions != nil {
+			areaConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.AreaConditions {
+				areaCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"areas":      condition.Areas,
+				}
+				areaConditions = append(areaConditions, areaCondition)
+			}
+			condition["area_conditions"] = areaConditions
+		}
+		if item.Condition.MethodConditions != nil {
+			methodConditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.MethodConditions {
+				methodCondition := map[string]interface{}{
+					"match_type":     condition.MatchType,
+					"request_method": condition.RequestMethod,
+				}
+				methodConditions = append(methodConditions, methodCondition)
+			}
+			condition["method_conditions"] = methodConditions
+		}
+		if item.Condition.Ja3Conditions != nil {
+			ja3Conditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.Ja3Conditions {
+				ja3Condition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ja3_list":   condition.Ja3List,
+				}
+				ja3Conditions = append(ja3Conditions, ja3Condition)
+			}
+			condition["ja3_conditions"] = ja3Conditions
+		}
+		if item.Condition.Ja4Conditions != nil {
+			ja4Conditions := make([]interface{}, 0)
+			for _, condition := range item.Condition.Ja4Conditions {
+				ja4Condition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ja4_list":   condition.Ja4List,
+				}
+				ja4Conditions = append(ja4Conditions, ja4Condition)
+			}
+			condition["ja4_conditions"] = ja4Conditions
 		}
 	}
+	_ = data.Set("condition", condition)
 	return nil
 }
 
@@ -976,14 +991,23 @@ func resourceWaapShareCustomizeRuleUpdate(context context.Context, data *schema.
 	var response *waapShareCustomizerule.UpdateSharedCustomRulesResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeruleClient().Update(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "Update", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Update(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -1004,14 +1028,23 @@ func resourceWaapShareCustomizeRuleDelete(context context.Context, data *schema.
 		request := &waapShareCustomizerule.DeleteSharedCustomRulesRequest{
 			IdList: []*string{&id},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareCustomizeruleClient().Delete(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareCustomizeruleClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "Delete", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.Delete(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapShareWhitelist "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/share-whitelist"
 	"log"
 	"time"
@@ -17,6 +18,7 @@ func DataSourceWaapShareWhitelists() *schema.Resource {
 		ReadContext: dataSourceWaapShareWhitelistsRead,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"rule_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -215,7 +217,16 @@ func dataSourceWaapShareWhitelistsRead(context context.Context, data *schema.Res
 		request.SetRuleName(v.(string))
 	}
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareWhitelistClient().GetWaapShareWhitelistList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetWaapShareWhitelistList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetWaapShareWhitelistList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -223,7 +234,7 @@ func dataSourceWaapShareWhitelistsRead(context context.Context, data *schema.Res
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
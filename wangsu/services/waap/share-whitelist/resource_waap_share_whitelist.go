@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapShareWhitelist "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/share-whitelist"
 	"log"
 	"time"
@@ -19,6 +20,7 @@ func ResourceWaapShareWhitelist() *schema.Resource {
 		DeleteContext: resourceWaapShareWhitelistDelete,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -346,7 +348,16 @@ func resourceWaapShareWhitelistCreate(context context.Context, data *schema.Reso
 	var response *waapShareWhitelist.CreateShareWhitelistRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareWhitelistClient().AddWaapShareWhitelistRule(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "AddWaapShareWhitelistRule", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.AddWaapShareWhitelistRule(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -354,7 +365,7 @@ func resourceWaapShareWhitelistCreate(context context.Context, data *schema.Reso
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -374,7 +385,16 @@ func resourceWaapShareWhitelistRead(context context.Context, data *schema.Resour
 	var diags diag.Diagnostics
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
 		request := &waapShareWhitelist.ListShareWhitelistRulesRequest{}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareWhitelistClient().GetWaapShareWhitelistList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetWaapShareWhitelistList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetWaapShareWhitelistList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -382,99 +402,94 @@ func resourceWaapShareWhitelistRead(context context.Context, data *schema.Resour
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_share_whitelist")
 	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
-			}
-			_ = data.Set("id", item.Id)
-			_ = data.Set("relation_domain_list", item.RelationDomainList)
-			_ = data.Set("rule_name", item.RuleName)
-			_ = data.Set("description", item.Description)
-			if item.Conditions != nil {
-				conditions := make(map[string]interface{})
-				if item.Conditions.IpOrIpsConditions != nil {
-					ipOrIpsConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.IpOrIpsConditions {
-						ipOrIpsCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ip_or_ips":  condition.IpOrIps,
-						}
-						ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
-					}
-					conditions["ip_or_ips_conditions"] = ipOrIpsConditions
+	item := response.Data[index]
+	_ = data.Set("id", item.Id)
+	_ = data.Set("relation_domain_list", item.RelationDomainList)
+	_ = data.Set("rule_name", item.RuleName)
+	_ = data.Set("description", item.Description)
+	if item.Conditions != nil {
+		conditions := make(map[string]interface{})
+		if item.Conditions.IpOrIpsConditions != nil {
+			ipOrIpsConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.IpOrIpsConditions {
+				ipOrIpsCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ip_or_ips":  condition.IpOrIps,
 				}
-				if item.Conditions.PathConditions != nil {
-					pathConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.PathConditions {
-						pathCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"paths":      condition.Paths,
-						}
-						pathConditions = append(pathConditions, pathCondition)
-					}
-					conditions["path_conditions"] = pathConditions
+				ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
+			}
+			conditions["ip_or_ips_conditions"] = ipOrIpsConditions
+		}
+		if item.Conditions.PathConditions != nil {
+			pathConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.PathConditions {
+				pathCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"paths":      condition.Paths,
 				}
-				if item.Conditions.UriConditions != nil {
-					uriConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.UriConditions {
-						uriCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"uri":        condition.Uri,
-						}
-						uriConditions = append(uriConditions, uriCondition)
-					}
-					conditions["uri_conditions"] = uriConditions
+				pathConditions = append(pathConditions, pathCondition)
+			}
+			conditions["path_conditions"] = pathConditions
+		}
+		if item.Conditions.UriConditions != nil {
+			uriConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.UriConditions {
+				uriCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"uri":        condition.Uri,
 				}
-				if item.Conditions.UaConditions != nil {
-					uaConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.UaConditions {
-						uaCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ua":         condition.Ua,
-						}
-						uaConditions = append(uaConditions, uaCondition)
-					}
-					conditions["ua_conditions"] = uaConditions
+				uriConditions = append(uriConditions, uriCondition)
+			}
+			conditions["uri_conditions"] = uriConditions
+		}
+		if item.Conditions.UaConditions != nil {
+			uaConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.UaConditions {
+				uaCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ua":         condition.Ua,
 				}
-				if item.Conditions.RefererConditions != nil {
-					refererConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.RefererConditions {
-						refererCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"referer":    condition.Referer,
-						}
-						refererConditions = append(refererConditions, refererCondition)
-					}
-					conditions["referer_conditions"] = refererConditions
+				uaConditions = append(uaConditions, uaCondition)
+			}
+			conditions["ua_conditions"] = uaConditions
+		}
+		if item.Conditions.RefererConditions != nil {
+			refererConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.RefererConditions {
+				refererCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"referer":    condition.Referer,
 				}
-				if item.Conditions.HeaderConditions != nil {
-					headerConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.HeaderConditions {
-						headerCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"key":        condition.Key,
-							"value_list": condition.ValueList,
-						}
-						headerConditions = append(headerConditions, headerCondition)
-					}
-					conditions["header_conditions"] = headerConditions
+				refererConditions = append(refererConditions, refererCondition)
+			}
+			conditions["referer_conditions"] = refererConditions
+		}
+		if item.Conditions.HeaderConditions != nil {
+			headerConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.HeaderConditions {
+				headerCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"key":        condition.Key,
+					"value_list": condition.ValueList,
 				}
-				_ = data.Set("conditions", conditions)
+				headerConditions = append(headerConditions, headerCondition)
 			}
+			conditions["header_conditions"] = headerConditions
 		}
+		_ = data.Set("conditions", conditions)
 	}
 	return nil
 }
@@ -635,7 +650,16 @@ func resourceWaapShareWhitelistUpdate(context context.Context, data *schema.Reso
 	var response *waapShareWhitelist.UpdateShareWhitelistRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareWhitelistClient().UpdateWaapShareWhitelist(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateWaapShareWhitelist", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateWaapShareWhitelist(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -643,7 +667,7 @@ func resourceWaapShareWhitelistUpdate(context context.Context, data *schema.Reso
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -664,7 +688,16 @@ func resourceWaapShareWhitelistDelete(context context.Context, data *schema.Reso
 		requset := &waapShareWhitelist.DeleteShareWhitelistRuleRequest{
 			IdList: []*string{&id},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapShareWhitelistClient().DeleteWaapShareWhitelist(requset)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapShareWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "DeleteWaapShareWhitelist", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.DeleteWaapShareWhitelist(requset)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -672,7 +705,7 @@ func resourceWaapShareWhitelistDelete(context context.Context, data *schema.Reso
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
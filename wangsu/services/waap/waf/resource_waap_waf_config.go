@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	securityPolicy "github.com/wangsu-api/wangsu-sdk-go/wangsu/securitypolicy"
 	"log"
 	"time"
@@ -24,6 +25,7 @@ func ResourceWaapWafConfig() *schema.Resource {
 		},
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"domain": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -200,6 +202,10 @@ func readWafConfig(context context.Context, data *schema.ResourceData, meta inte
 		data.SetId(domain.(string))
 	}
 	diags = append(diags, readBasicConf(context, data, meta)...)
+	if data.Id() == "" {
+		// the domain no longer exists
+		return diags
+	}
 	diags = append(diags, readRule(context, data, meta)...)
 	diags = append(diags, readScanProtectionConf(context, data, meta)...)
 	return diags
@@ -207,6 +213,11 @@ func readWafConfig(context context.Context, data *schema.ResourceData, meta inte
 
 func updateWafConfig(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_waf_config.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 	var diags diag.Diagnostics
 	if data.HasChange("domain") {
 		// 把domain强制刷回旧值，否则会有权限问题
@@ -245,7 +256,16 @@ func readBasicConf(context context.Context, data *schema.ResourceData, meta inte
 		domain := data.Id()
 		request.SetDomainList([]*string{&domain})
 
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().GetWafConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetWafConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetWafConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -253,7 +273,7 @@ func readBasicConf(context context.Context, data *schema.ResourceData, meta inte
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -303,7 +323,16 @@ func updateBasicConf(context context.Context, data *schema.ResourceData, meta in
 			var response *securityPolicy.UpdateModeOfWAFResponse
 			var err error
 			err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-				_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().UpdateWafConfig(request)
+				conn := wangsuCommon.APIConn(data, meta)
+				client, clientErr := conn.UseSecurityPolicyClient()
+				if clientErr != nil {
+					return resource.NonRetryableError(clientErr)
+				}
+				err = conn.Call(context, connectivity.EndpointWaap, "UpdateWafConfig", func() (string, error) {
+					var requestId string
+					requestId, response, err = client.UpdateWafConfig(request)
+					return requestId, wangsuCommon.NewAPIError(err, requestId)
+				})
 				if err != nil {
 					return resource.NonRetryableError(err)
 				}
@@ -311,7 +340,7 @@ func updateBasicConf(context context.Context, data *schema.ResourceData, meta in
 			})
 
 			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
+				diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 				return diags
 			}
 			if response == nil {
@@ -334,7 +363,16 @@ func readRule(context context.Context, data *schema.ResourceData, meta interface
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
 		request.Domain = &domain
 
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().GetWafRule(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetWafRule", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetWafRule(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -342,7 +380,7 @@ func readRule(context context.Context, data *schema.ResourceData, meta interface
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -409,7 +447,16 @@ func updateRule(context context.Context, data *schema.ResourceData, meta interfa
 		var response *securityPolicy.UpdateActionForWAFManagedRulesResponse
 		var err error
 		err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-			_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().UpdateWafRule(request)
+			conn := wangsuCommon.APIConn(data, meta)
+			client, clientErr := conn.UseSecurityPolicyClient()
+			if clientErr != nil {
+				return resource.NonRetryableError(clientErr)
+			}
+			err = conn.Call(context, connectivity.EndpointWaap, "UpdateWafRule", func() (string, error) {
+				var requestId string
+				requestId, response, err = client.UpdateWafRule(request)
+				return requestId, wangsuCommon.NewAPIError(err, requestId)
+			})
 			if err != nil {
 				return resource.NonRetryableError(err)
 			}
@@ -417,7 +464,7 @@ func updateRule(context context.Context, data *schema.ResourceData, meta interfa
 		})
 
 		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+			diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 			return diags
 		}
 		if response == nil {
@@ -438,7 +485,16 @@ func readScanProtectionConf(context context.Context, data *schema.ResourceData,
 		domain := data.Id()
 		request.SetDomainList([]*string{&domain})
 
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().GetWAFScanProtectionConfig(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetWAFScanProtectionConfig", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetWAFScanProtectionConfig(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -446,7 +502,7 @@ func readScanProtectionConf(context context.Context, data *schema.ResourceData,
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
@@ -617,7 +673,16 @@ func updateScanProtectionConf(context context.Context, data *schema.ResourceData
 			var response *securityPolicy.UpdateWAFScanProtectionConfigResponse
 			var err error
 			err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-				_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().UpdateWAFScanProtectionConfig(request)
+				conn := wangsuCommon.APIConn(data, meta)
+				client, clientErr := conn.UseSecurityPolicyClient()
+				if clientErr != nil {
+					return resource.NonRetryableError(clientErr)
+				}
+				err = conn.Call(context, connectivity.EndpointWaap, "UpdateWAFScanProtectionConfig", func() (string, error) {
+					var requestId string
+					requestId, response, err = client.UpdateWAFScanProtectionConfig(request)
+					return requestId, wangsuCommon.NewAPIError(err, requestId)
+				})
 				if err != nil {
 					return resource.NonRetryableError(err)
 				}
@@ -625,7 +690,7 @@ func updateScanProtectionConf(context context.Context, data *schema.ResourceData
 			})
 
 			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
+				diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 				return diags
 			}
 			if response == nil {
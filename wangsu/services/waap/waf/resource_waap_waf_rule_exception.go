@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	securityPolicy "github.com/wangsu-api/wangsu-sdk-go/wangsu/securitypolicy"
 	"log"
 	"time"
@@ -20,6 +21,7 @@ func ResourceWaapWafRuleException() *schema.Resource {
 		DeleteContext: deleteWafRuleException,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -60,6 +62,11 @@ func ResourceWaapWafRuleException() *schema.Resource {
 
 func createWafRuleException(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_waf_rule_exception.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &securityPolicy.CreateExceptionToWAFManagedRulesRequest{}
@@ -92,7 +99,16 @@ func createWafRuleException(context context.Context, data *schema.ResourceData,
 	var response *securityPolicy.CreateExceptionToWAFManagedRulesResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().CreateExceptionToWAFManagedRules(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "CreateExceptionToWAFManagedRules", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.CreateExceptionToWAFManagedRules(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -100,7 +116,7 @@ func createWafRuleException(context context.Context, data *schema.ResourceData,
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -127,7 +143,16 @@ func readWafRuleException(context context.Context, data *schema.ResourceData, me
 			DomainList: []*string{&domain},
 			RuleIdList: []*int{&ruleId},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().ListNonSharedWAFRuleExceptionsForWAFRules(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "ListNonSharedWAFRuleExceptionsForWAFRules", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.ListNonSharedWAFRuleExceptionsForWAFRules(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -135,36 +160,36 @@ func readWafRuleException(context context.Context, data *schema.ResourceData, me
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
-	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
-			}
-			_ = data.Set("domain", item.Domain)
-			_ = data.Set("rule_id", item.RuleId)
-			_ = data.Set("type", item.Type)
-			_ = data.Set("match_type", item.MatchType)
-			_ = data.Set("content_list", item.ContentList)
-		}
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_waf_rule_exception")
 	}
+	item := response.Data[index]
+	_ = data.Set("domain", item.Domain)
+	_ = data.Set("rule_id", item.RuleId)
+	_ = data.Set("type", item.Type)
+	_ = data.Set("match_type", item.MatchType)
+	_ = data.Set("content_list", item.ContentList)
 
 	return nil
 }
 
 func updateWafRuleException(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_waf_rule_exception.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 	var diags diag.Diagnostics
 	var canNotChange = false
 	if data.HasChange("domain") {
@@ -216,14 +241,23 @@ func updateWafRuleException(context context.Context, data *schema.ResourceData,
 	var response *securityPolicy.UpdateExceptionForWAFManagedRulesResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().UpdateExceptionForWAFManagedRules(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateExceptionForWAFManagedRules", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateExceptionForWAFManagedRules(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -235,6 +269,11 @@ func updateWafRuleException(context context.Context, data *schema.ResourceData,
 
 func deleteWafRuleException(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.waap_waf_rule_exception.delete")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 	request := &securityPolicy.DeleteExceptionForWAFManagedRulesRequest{}
 	var response *securityPolicy.DeleteExceptionForWAFManagedRulesResponse
 	var err error
@@ -255,14 +294,23 @@ func deleteWafRuleException(context context.Context, data *schema.ResourceData,
 	request.DelDTOList = dtoList
 
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseSecurityPolicyClient().DeleteExceptionForWAFManagedRules(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseSecurityPolicyClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "DeleteExceptionForWAFManagedRules", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.DeleteExceptionForWAFManagedRules(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 		return nil
 	})
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
 	waapWhitelist "github.com/wangsu-api/wangsu-sdk-go/wangsu/waap/whitelist"
 	"log"
 	"time"
@@ -20,6 +21,7 @@ func ResourceWaapWhitelist() *schema.Resource {
 		DeleteContext: resourceWaapWhitelistDelete,
 
 		Schema: map[string]*schema.Schema{
+			"service_type": wangsuCommon.ServiceTypeSchema(),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -192,6 +194,11 @@ func ResourceWaapWhitelist() *schema.Resource {
 
 func resourceWaapWhitelistCreate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_whitelist.create")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var diags diag.Diagnostics
 	request := &waapWhitelist.CreateWhitelistRuleRequest{}
@@ -336,7 +343,16 @@ func resourceWaapWhitelistCreate(context context.Context, data *schema.ResourceD
 	var response *waapWhitelist.CreateWhitelistRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapWhitelistClient().AddWaapWhitelistRule(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "AddWaapWhitelistRule", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.AddWaapWhitelistRule(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -344,7 +360,7 @@ func resourceWaapWhitelistCreate(context context.Context, data *schema.ResourceD
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil || response.Data == nil {
@@ -372,7 +388,16 @@ func resourceWaapWhitelistRead(context context.Context, data *schema.ResourceDat
 			DomainList: []*string{&domain},
 			//RuleName:   &ruleName,
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapWhitelistClient().GetWaapWhitelistList(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "GetWaapWhitelistList", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.GetWaapWhitelistList(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -380,105 +405,105 @@ func resourceWaapWhitelistRead(context context.Context, data *schema.ResourceDat
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
 	if response == nil {
 		return nil
 	}
-	if len(response.Data) == 0 {
-		data.SetId("")
-		return nil
+	index := wangsuCommon.IndexOfId(len(response.Data), data.Id(), func(i int) *string {
+		return response.Data[i].Id
+	})
+	if index < 0 {
+		return wangsuCommon.RemoveFromState(data, "wangsu_waap_whitelist")
 	}
-	if response.Data != nil {
-		for _, item := range response.Data {
-			// 只要对应id的数据
-			if *item.Id != data.Id() {
-				continue
-			}
-			_ = data.Set("id", item.Id)
-			_ = data.Set("domain", item.Domain)
-			_ = data.Set("rule_name", item.RuleName)
-			_ = data.Set("description", item.Description)
-			if item.Conditions != nil {
-				conditions := make(map[string]interface{})
-				if item.Conditions.IpOrIpsConditions != nil {
-					ipOrIpsConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.IpOrIpsConditions {
-						ipOrIpsCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ip_or_ips":  condition.IpOrIps,
-						}
-						ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
-					}
-					conditions["ip_or_ips_conditions"] = ipOrIpsConditions
+	item := response.Data[index]
+	_ = data.Set("id", item.Id)
+	_ = data.Set("domain", item.Domain)
+	_ = data.Set("rule_name", item.RuleName)
+	_ = data.Set("description", item.Description)
+	if item.Conditions != nil {
+		conditions := make(map[string]interface{})
+		if item.Conditions.IpOrIpsConditions != nil {
+			ipOrIpsConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.IpOrIpsConditions {
+				ipOrIpsCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ip_or_ips":  condition.IpOrIps,
 				}
-				if item.Conditions.PathConditions != nil {
-					pathConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.PathConditions {
-						pathCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"paths":      condition.Paths,
-						}
-						pathConditions = append(pathConditions, pathCondition)
-					}
-					conditions["path_conditions"] = pathConditions
+				ipOrIpsConditions = append(ipOrIpsConditions, ipOrIpsCondition)
+			}
+			conditions["ip_or_ips_conditions"] = ipOrIpsConditions
+		}
+		if item.Conditions.PathConditions != nil {
+			pathConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.PathConditions {
+				pathCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"paths":      condition.Paths,
 				}
-				if item.Conditions.UriConditions != nil {
-					uriConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.UriConditions {
-						uriCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"uri":        condition.Uri,
-						}
-						uriConditions = append(uriConditions, uriCondition)
-					}
-					conditions["uri_conditions"] = uriConditions
+				pathConditions = append(pathConditions, pathCondition)
+			}
+			conditions["path_conditions"] = pathConditions
+		}
+		if item.Conditions.UriConditions != nil {
+			uriConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.UriConditions {
+				uriCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"uri":        condition.Uri,
 				}
-				if item.Conditions.UaConditions != nil {
-					uaConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.UaConditions {
-						uaCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"ua":         condition.Ua,
-						}
-						uaConditions = append(uaConditions, uaCondition)
-					}
-					conditions["ua_conditions"] = uaConditions
+				uriConditions = append(uriConditions, uriCondition)
+			}
+			conditions["uri_conditions"] = uriConditions
+		}
+		if item.Conditions.UaConditions != nil {
+			uaConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.UaConditions {
+				uaCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"ua":         condition.Ua,
 				}
-				if item.Conditions.RefererConditions != nil {
-					refererConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.RefererConditions {
-						refererCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"referer":    condition.Referer,
-						}
-						refererConditions = append(refererConditions, refererCondition)
-					}
-					conditions["referer_conditions"] = refererConditions
+				uaConditions = append(uaConditions, uaCondition)
+			}
+			conditions["ua_conditions"] = uaConditions
+		}
+		if item.Conditions.RefererConditions != nil {
+			refererConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.RefererConditions {
+				refererCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"referer":    condition.Referer,
 				}
-				if item.Conditions.HeaderConditions != nil {
-					headerConditions := make([]interface{}, 0)
-					for _, condition := range item.Conditions.HeaderConditions {
-						headerCondition := map[string]interface{}{
-							"match_type": condition.MatchType,
-							"key":        condition.Key,
-							"value_list": condition.ValueList,
-						}
-						headerConditions = append(headerConditions, headerCondition)
-					}
-					conditions["header_conditions"] = headerConditions
+				refererConditions = append(refererConditions, refererCondition)
+			}
+			conditions["referer_conditions"] = refererConditions
+		}
+		if item.Conditions.HeaderConditions != nil {
+			headerConditions := make([]interface{}, 0)
+			for _, condition := range item.Conditions.HeaderConditions {
+				headerCondition := map[string]interface{}{
+					"match_type": condition.MatchType,
+					"key":        condition.Key,
+					"value_list": condition.ValueList,
 				}
-				_ = data.Set("conditions", conditions)
+				headerConditions = append(headerConditions, headerCondition)
 			}
+			conditions["header_conditions"] = headerConditions
 		}
+		_ = data.Set("conditions", conditions)
 	}
 	return nil
 }
 
 func resourceWaapWhitelistUpdate(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_whitelist.update")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 	var diags diag.Diagnostics
 	if data.HasChange("domain") {
 		// 把domain强制刷回旧值，否则会有权限问题
@@ -631,7 +656,16 @@ func resourceWaapWhitelistUpdate(context context.Context, data *schema.ResourceD
 	var response *waapWhitelist.UpdateWhitelistRuleResponse
 	var err error
 	err = resource.RetryContext(context, time.Duration(2)*time.Minute, func() *resource.RetryError {
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapWhitelistClient().UpdateWaapWhitelist(request)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "UpdateWaapWhitelist", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.UpdateWaapWhitelist(request)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -639,7 +673,7 @@ func resourceWaapWhitelistUpdate(context context.Context, data *schema.ResourceD
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 	if response == nil {
@@ -651,6 +685,11 @@ func resourceWaapWhitelistUpdate(context context.Context, data *schema.ResourceD
 
 func resourceWaapWhitelistDelete(context context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("resource.wangsu_waap_whitelist.delete")
+	unlock, lockErr := wangsuCommon.LockDomain(context, data.Get("domain").(string))
+	if lockErr != nil {
+		return diag.FromErr(lockErr)
+	}
+	defer unlock()
 
 	var response *waapWhitelist.DeleteWhitelistRulesResponse
 	var err error
@@ -660,7 +699,16 @@ func resourceWaapWhitelistDelete(context context.Context, data *schema.ResourceD
 		requset := &waapWhitelist.DeleteWhitelistRulesRequest{
 			IdList: []*string{&id},
 		}
-		_, response, err = meta.(wangsuCommon.ProviderMeta).GetAPIV3Conn().UseWaapWhitelistClient().DeleteWaapWhitelist(requset)
+		conn := wangsuCommon.APIConn(data, meta)
+		client, clientErr := conn.UseWaapWhitelistClient()
+		if clientErr != nil {
+			return resource.NonRetryableError(clientErr)
+		}
+		err = conn.Call(context, connectivity.EndpointWaap, "DeleteWaapWhitelist", func() (string, error) {
+			var requestId string
+			requestId, response, err = client.DeleteWaapWhitelist(requset)
+			return requestId, wangsuCommon.NewAPIError(err, requestId)
+		})
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
@@ -668,7 +716,7 @@ func resourceWaapWhitelistDelete(context context.Context, data *schema.ResourceD
 	})
 
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, wangsuCommon.DiagnosticsFromError(data, err)...)
 		return diags
 	}
 
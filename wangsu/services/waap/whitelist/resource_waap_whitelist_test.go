@@ -0,0 +1,59 @@
+package whitelist_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/emulator"
+)
+
+func TestAccWaapWhitelist_basic(t *testing.T) {
+	server := emulator.New()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: server.ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			if n := server.Collection("waap_whitelist").Len(); n > 0 {
+				return fmt.Errorf("%d whitelist rules left after destroy", n)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: server.ProviderConfig() + testAccWaapWhitelistConfig("tf-acc-test rule"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("wangsu_waap_whitelist.test", "id"),
+					resource.TestCheckResourceAttr("wangsu_waap_whitelist.test", "description", "tf-acc-test rule"),
+					resource.TestCheckResourceAttr("wangsu_waap_whitelist.test", "conditions.0.path_conditions.0.paths.#", "2"),
+				),
+			},
+			{
+				Config: server.ProviderConfig() + testAccWaapWhitelistConfig("tf-acc-test rule updated"),
+				Check:  resource.TestCheckResourceAttr("wangsu_waap_whitelist.test", "description", "tf-acc-test rule updated"),
+			},
+		},
+	})
+	if err := server.CheckRoutes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func testAccWaapWhitelistConfig(description string) string {
+	return fmt.Sprintf(`
+resource "wangsu_waap_whitelist" "test" {
+  domain      = "tf-acc-test.example.com"
+  rule_name   = "tf_acc_test"
+  description = %q
+
+  conditions {
+    path_conditions {
+      match_type = "EQUAL"
+      paths      = ["/health", "/status"]
+    }
+  }
+}
+`, description)
+}
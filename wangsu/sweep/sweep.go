@@ -0,0 +1,205 @@
+// Package sweep holds the helpers of the test sweepers, which delete the objects
+// that aborted acceptance tests leave behind in the test account. The service
+// packages register their sweepers in sweeper_test.go files, so that neither
+// the sweepers nor the test framework end up in the provider binary; they only
+// touch objects whose name starts with Prefix().
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	wangsuCommon "github.com/wangsu-api/terraform-provider-wangsu/wangsu/common"
+	"github.com/wangsu-api/terraform-provider-wangsu/wangsu/connectivity"
+	sdkCommon "github.com/wangsu-api/wangsu-sdk-go/wangsu/common"
+)
+
+const (
+	// PrefixEnv is the environment variable holding the name prefix of the
+	// objects to sweep.
+	PrefixEnv = "WANGSU_SWEEP_PREFIX"
+	// DefaultPrefix is the name prefix used by the acceptance tests.
+	DefaultPrefix = "tf-acc-test"
+)
+
+// Prefix returns the name prefix of the objects to sweep.
+func Prefix() string {
+	if prefix := os.Getenv(PrefixEnv); prefix != "" {
+		return prefix
+	}
+	return DefaultPrefix
+}
+
+// HasPrefix reports whether any of values, typically a name and a comment,
+// starts with Prefix(). Matching ignores case.
+func HasPrefix(values ...interface{}) bool {
+	prefix := strings.ToLower(Prefix())
+	for _, value := range values {
+		var text string
+		switch value := value.(type) {
+		case string:
+			text = value
+		case *string:
+			if value != nil {
+				text = *value
+			}
+		}
+		if text != "" && strings.HasPrefix(strings.ToLower(text), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type providerMeta struct {
+	client *connectivity.WangSuClient
+}
+
+func (m *providerMeta) GetAPIV3Conn() *connectivity.WangSuClient {
+	return m.client
+}
+
+var (
+	sharedMetaOnce sync.Once
+	sharedMeta     *providerMeta
+	sharedMetaErr  error
+)
+
+// SharedMeta returns the provider meta the sweepers use, configured from the same
+// environment variables and shared credentials file as the provider. Wangsu has
+// no regions, region is ignored.
+func SharedMeta(region string) (wangsuCommon.ProviderMeta, error) {
+	sharedMetaOnce.Do(func() {
+		sharedMeta, sharedMetaErr = newSharedMeta()
+	})
+	return sharedMeta, sharedMetaErr
+}
+
+func newSharedMeta() (*providerMeta, error) {
+	secretId := os.Getenv("WANGSU_SECRET_ID")
+	secretKey := os.Getenv("WANGSU_SECRET_KEY")
+	domain := os.Getenv("WANGSU_DOMAIN")
+	site := os.Getenv("WANGSU_SITE")
+	protocol := os.Getenv("WANGSU_PROTOCOL")
+	credentialsFile, profile := os.Getenv("WANGSU_SHARED_CREDENTIALS_FILE"), os.Getenv("WANGSU_PROFILE")
+	sharedProfile, err := connectivity.LoadSharedProfile(credentialsFile, profile, credentialsFile != "" || profile != "")
+	if err != nil {
+		return nil, err
+	}
+	if sharedProfile != nil {
+		if secretId == "" {
+			secretId = sharedProfile.SecretId
+		}
+		if secretKey == "" {
+			secretKey = sharedProfile.SecretKey
+		}
+		if domain == "" {
+			domain = sharedProfile.Domain
+		}
+		if site == "" {
+			site = sharedProfile.Site
+		}
+		if protocol == "" {
+			protocol = sharedProfile.Protocol
+		}
+	}
+	if secretId == "" || secretKey == "" {
+		return nil, errors.New("sweepers need credentials: set WANGSU_SECRET_ID and WANGSU_SECRET_KEY or a shared credentials file")
+	}
+	if protocol == "" {
+		protocol = "https"
+	}
+	if site != "" && site != connectivity.SiteCn && site != connectivity.SiteIntl {
+		return nil, fmt.Errorf("WANGSU_SITE must be cn or intl, got %q", site)
+	}
+	if domain == "" && site != "" {
+		domain = connectivity.SiteDomain(site)
+	}
+
+	client := &connectivity.WangSuClient{
+		Credential: sdkCommon.NewCredential(secretId, secretKey),
+		Domain:     domain,
+		Protocol:   protocol,
+		RetryPolicy: connectivity.RetryPolicy{
+			MaxRetries: 5,
+			MaxWait:    30 * time.Second,
+		},
+		HTTPOptions: connectivity.HTTPOptions{
+			CABundleFile:       os.Getenv("WANGSU_CA_BUNDLE_FILE"),
+			InsecureSkipVerify: os.Getenv("WANGSU_INSECURE_SKIP_VERIFY") == "true",
+			HTTPProxy:          os.Getenv("WANGSU_HTTP_PROXY"),
+		},
+		UserAgent:       connectivity.UserAgent("", ""),
+		UserAgentSuffix: "sweeper",
+	}
+	if _, err := client.HTTPTransport(); err != nil {
+		return nil, err
+	}
+	return &providerMeta{client: client}, nil
+}
+
+// Read runs the read function of a data source with the given arguments and
+// returns its data, e.g. to list objects through wangsu_cdn_domains.
+func Read(ctx context.Context, dataSource *schema.Resource, arguments map[string]interface{}, meta interface{}) (*schema.ResourceData, error) {
+	data := dataSource.Data(nil)
+	for key, value := range arguments {
+		if err := data.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := diagnosticsError(dataSource.ReadContext(ctx, data, meta)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Delete runs the delete function of a resource on the object id. attributes are
+// the state attributes the delete function reads, such as the domain of a rule.
+func Delete(ctx context.Context, res *schema.Resource, id string, attributes map[string]interface{}, meta interface{}) error {
+	data := res.Data(nil)
+	data.SetId(id)
+	for key, value := range attributes {
+		if err := data.Set(key, value); err != nil {
+			return err
+		}
+	}
+	return diagnosticsError(res.DeleteContext(ctx, data, meta))
+}
+
+// Errors collects the errors of the objects of one sweeper, so that one object
+// that cannot be deleted does not keep the others.
+type Errors []error
+
+// Add records the error of one object, if any.
+func (e *Errors) Add(kind, id string, err error) {
+	if err != nil {
+		*e = append(*e, fmt.Errorf("%s %s: %w", kind, id, err))
+	}
+}
+
+// Err returns the recorded errors as one error, or nil.
+func (e Errors) Err() error {
+	return errors.Join(e...)
+}
+
+func diagnosticsError(diags diag.Diagnostics) error {
+	var errs []error
+	for _, d := range diags {
+		if d.Severity != diag.Error {
+			continue
+		}
+		if d.Detail != "" {
+			errs = append(errs, fmt.Errorf("%s: %s", d.Summary, d.Detail))
+		} else {
+			errs = append(errs, errors.New(d.Summary))
+		}
+	}
+	return errors.Join(errs...)
+}